@@ -7,12 +7,14 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"time"
 
 	"github.com/mdouchement/logger"
 	"github.com/mdouchement/openstackswift/internal/database"
 	"github.com/mdouchement/openstackswift/internal/scheduler"
 	"github.com/mdouchement/openstackswift/internal/storage"
 	"github.com/mdouchement/openstackswift/internal/webserver"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -25,8 +27,10 @@ var (
 	revision = "none"
 	date     = "unknown"
 
-	binding string
-	port    string
+	binding     string
+	port        string
+	domainname  string
+	projectname string
 )
 
 func main() {
@@ -47,6 +51,13 @@ func main() {
 	c.AddCommand(initCmd)
 	c.AddCommand(reindexCmd)
 
+	userCmd.PersistentFlags().StringVarP(&domainname, "domain", "d", "Default", "User's domain")
+	userAddCmd.Flags().StringVarP(&projectname, "project", "t", "test", "User's project")
+	userCmd.AddCommand(userAddCmd)
+	userCmd.AddCommand(userListCmd)
+	userCmd.AddCommand(userDeleteCmd)
+	c.AddCommand(userCmd)
+
 	serverCmd.Flags().StringVarP(&binding, "binding", "b", "0.0.0.0", "Server's binding")
 	serverCmd.Flags().StringVarP(&port, "port", "p", "5000", "Server's port")
 	c.AddCommand(serverCmd)
@@ -79,18 +90,112 @@ var (
 
 	//
 
+	userCmd = &cobra.Command{
+		Use:   "user",
+		Short: "Manage the users registry",
+	}
+
+	userAddCmd = &cobra.Command{
+		Use:   "add USERNAME PASSWORD",
+		Short: "Add or update a user",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			db, err := database.StormOpen(nameWithEnv("DATABASE_PATH", dbname))
+			if err != nil {
+				return errors.Wrap(err, "could not open database")
+			}
+			defer db.Close()
+
+			user, err := database.RegisterUser(db, domainname, projectname, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("User %s added to project %s (account AUTH_%s)\n", user.Name, projectname, user.ProjectID)
+			return nil
+		},
+	}
+
+	userListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all users",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			db, err := database.StormOpen(nameWithEnv("DATABASE_PATH", dbname))
+			if err != nil {
+				return errors.Wrap(err, "could not open database")
+			}
+			defer db.Close()
+
+			users, err := db.ListUsers()
+			if err != nil {
+				return err
+			}
+
+			for _, user := range users {
+				domain, err := db.FindDomain(user.DomainID)
+				if err != nil {
+					return err
+				}
+
+				project, err := db.FindProject(user.ProjectID)
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("%-10s %-10s %-10s %s\n", domain.Name, project.Name, user.Name, project.Account())
+			}
+			return nil
+		},
+	}
+
+	userDeleteCmd = &cobra.Command{
+		Use:   "delete USERNAME",
+		Short: "Delete a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			db, err := database.StormOpen(nameWithEnv("DATABASE_PATH", dbname))
+			if err != nil {
+				return errors.Wrap(err, "could not open database")
+			}
+			defer db.Close()
+
+			domain, err := db.FindDomainByName(domainname)
+			if err != nil {
+				return err
+			}
+
+			user, err := db.FindUserByName(domain.ID, args[0])
+			if err != nil {
+				return err
+			}
+
+			if err = db.DeleteTokensByUserID(user.ID); err != nil {
+				return err
+			}
+			if err = db.DeleteApplicationCredentialsByUserID(user.ID); err != nil {
+				return err
+			}
+			return db.DeleteUser(user.ID)
+		},
+	}
+
+	//
+
 	serverCmd = &cobra.Command{
 		Use:   "server",
 		Short: "Start server",
 		Args:  cobra.ExactArgs(0),
 		RunE: func(c *cobra.Command, _ []string) error {
+			ttl, err := time.ParseDuration(envORdefault("SWIFT_TOKEN_TTL", "1h"))
+			if err != nil {
+				return errors.Wrap(err, "SWIFT_TOKEN_TTL")
+			}
+
 			ctrl := webserver.Controller{
-				Version: c.Parent().Version,
-				//
-				Tenant:   envORdefault("SWIFT_STORAGE_TENANT", "test"),
+				Version:  c.Parent().Version,
+				TokenTTL: ttl,
 				Domain:   envORdefault("SWIFT_STORAGE_DOMAIN", "Default"),
-				Username: envORdefault("SWIFT_STORAGE_USERNAME", "tester"),
-				Password: envORdefault("SWIFT_STORAGE_PASSWORD", "testing"),
 			}
 
 			//
@@ -115,10 +220,25 @@ var (
 			defer db.Close()
 			ctrl.Database = db
 
+			user, err := database.RegisterUser(db,
+				envORdefault("SWIFT_STORAGE_DOMAIN", "Default"),
+				envORdefault("SWIFT_STORAGE_TENANT", "test"),
+				envORdefault("SWIFT_STORAGE_USERNAME", "tester"),
+				envORdefault("SWIFT_STORAGE_PASSWORD", "testing"),
+			)
+			if err != nil {
+				return errors.Wrap(err, "could not register default user")
+			}
+
 			//
 
 			ctrl.Storage = storage.NewFileSystem(nameWithEnv("STORAGE_PATH", "storage"))
 
+			// The containers created before the accounts belong to the default user's project.
+			if err = service.AdoptContainers(db, ctrl.Storage, user.ProjectID); err != nil {
+				return errors.Wrap(err, "could not adopt containers")
+			}
+
 			//
 
 			scheduler.Start(scheduler.Controller{
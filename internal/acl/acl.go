@@ -0,0 +1,95 @@
+package acl
+
+import (
+	"net/url"
+	"strings"
+)
+
+// An ACL is the parsed form of a X-Container-Read or X-Container-Write header.
+//
+// https://docs.openstack.org/swift/latest/overview_acl.html#container-acls
+type ACL struct {
+	// Referrers are the `.r:' designations, a leading `-' denies the host.
+	Referrers []string
+	// Groups are the identities (e.g. `project:user') and the `.rlistings' flag.
+	Groups []string
+}
+
+// Parse parses the comma separated elements of the given header value.
+func Parse(value string) ACL {
+	var acl ACL
+
+	for _, element := range strings.Split(value, ",") {
+		element = strings.TrimSpace(element)
+		if element == "" {
+			continue
+		}
+
+		designator, host, found := strings.Cut(element, ":")
+		if found {
+			switch strings.TrimSpace(designator) {
+			case ".r", ".ref", ".referer", ".referrer":
+				host = strings.TrimSpace(host)
+				if host == "" || host == "-" {
+					continue
+				}
+				acl.Referrers = append(acl.Referrers, host)
+				continue
+			}
+		}
+
+		acl.Groups = append(acl.Groups, element)
+	}
+
+	return acl
+}
+
+// Listings returns true if the ACL allows referrers to list the container.
+func (acl ACL) Listings() bool {
+	for _, group := range acl.Groups {
+		if group == ".rlistings" {
+			return true
+		}
+	}
+	return false
+}
+
+// Referrer returns true if the given Referer header is allowed by the ACL.
+func (acl ACL) Referrer(referrer string) bool {
+	rhost := "unknown"
+	if u, err := url.Parse(referrer); err == nil && u.Hostname() != "" {
+		rhost = u.Hostname()
+	}
+
+	allowed := false
+	for _, host := range acl.Referrers {
+		if deny, found := strings.CutPrefix(host, "-"); found {
+			if match(deny, rhost) {
+				allowed = false
+			}
+			continue
+		}
+
+		if host == "*" || match(host, rhost) {
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// Grants returns true if one of the given identities is granted by the ACL.
+func (acl ACL) Grants(identities ...string) bool {
+	for _, group := range acl.Groups {
+		for _, identity := range identities {
+			if group == identity {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// match returns true if the host matches the pattern, a leading `.' matches all the sub-domains.
+func match(pattern, host string) bool {
+	return pattern == host || (strings.HasPrefix(pattern, ".") && strings.HasSuffix(host, pattern))
+}
@@ -16,17 +16,61 @@ type (
 		// IsNotFound returns true if err is nil or a not found error.
 		IsNotFound(err error) bool
 
+		DomainInteraction
+		ProjectInteraction
+		UserInteraction
+		TokenInteraction
+		ApplicationCredentialInteraction
 		ContainerInteraction
 		ManifestInteraction
 		ObjectInteraction
+		VersionInteraction
 		MetaInteraction
 	}
 
+	// A DomainInteraction defines all the methods used to interact with a domain record.
+	DomainInteraction interface {
+		FindDomain(id string) (*model.Domain, error)
+		FindDomainByName(name string) (*model.Domain, error)
+	}
+
+	// A ProjectInteraction defines all the methods used to interact with a project record.
+	ProjectInteraction interface {
+		FindProject(id string) (*model.Project, error)
+		FindProjectByName(did, name string) (*model.Project, error)
+	}
+
+	// A UserInteraction defines all the methods used to interact with a user record.
+	UserInteraction interface {
+		ListUsers() ([]*model.User, error)
+		FindUser(id string) (*model.User, error)
+		FindUserByName(did, name string) (*model.User, error)
+		DeleteUser(id string) error
+	}
+
+	// A TokenInteraction defines all the methods used to interact with a token record.
+	TokenInteraction interface {
+		FindTokenByKey(key string) (*model.Token, error)
+		DeleteToken(id string) error
+		DeleteTokensByUserID(uid string) error
+		DeleteExpiredTokens() error
+	}
+
+	// An ApplicationCredentialInteraction defines all the methods used to interact with an application credential record.
+	ApplicationCredentialInteraction interface {
+		ListApplicationCredentials(uid string) ([]*model.ApplicationCredential, error)
+		FindApplicationCredential(id string) (*model.ApplicationCredential, error)
+		FindApplicationCredentialByName(uid, name string) (*model.ApplicationCredential, error)
+		DeleteApplicationCredential(id string) error
+		DeleteApplicationCredentialsByUserID(uid string) error
+	}
+
 	// A ContainerInteraction defines all the methods used to interact with a container record.
 	ContainerInteraction interface {
-		ListContainers() ([]*model.Container, error)
+		ListContainers(pid string) ([]*model.Container, error)
+		ListContainerEntries(pid string, opts ListOptions) ([]*ContainerEntry, error)
 		FindContainer(id string) (*model.Container, error)
-		FindContainerByName(name string) (*model.Container, error)
+		FindContainerByName(pid, name string) (*model.Container, error)
 		DeleteContainer(id string) error
 	}
 
@@ -40,15 +84,70 @@ type (
 	ObjectInteraction interface {
 		AllObjects() ([]*model.Object, error)
 		FindObjectsByContainerID(id string, limit int, prefix string) ([]*model.Object, error)
+		ListObjectEntries(cid string, opts ListOptions) ([]*ObjectEntry, error)
 		FindObjectsByManifestID(id string) ([]*model.Object, error)
 		FindObjectByKey(cid, key string) (*model.Object, error)
 		DeleteObject(id string) error
 	}
 
+	// A VersionInteraction defines all the methods used to interact with a version record.
+	VersionInteraction interface {
+		// FindVersions returns the versions of the container's objects matching the prefix, sorted by key and from the newest to the oldest.
+		FindVersions(cid, prefix string) ([]*model.Version, error)
+		FindVersion(cid, key, vid string) (*model.Version, error)
+		// ListVersionEntries returns the current and previous versions of the container's objects.
+		// The versions of an object are listed from the newest, even in reverse order.
+		ListVersionEntries(cid string, opts ListOptions) ([]*VersionEntry, error)
+		DeleteVersion(id string) error
+	}
+
+	// A ListOptions defines the Swift listing parameters.
+	ListOptions struct {
+		// Limit is the maximum number of entries, zero for no limit.
+		Limit int
+		// Marker and EndMarker are the exclusive bounds of the entries' names.
+		Marker    string
+		EndMarker string
+		// Prefix filters the entries' names.
+		Prefix string
+		// Delimiter rolls up the names sharing the same part after the prefix into a single subdir entry.
+		Delimiter string
+		// Path lists the entries nested directly under the given pseudo-directory.
+		Path string
+		// Reverse sorts the entries in descending order, the Marker is then the upper bound.
+		Reverse bool
+		// VersionMarker lists the versions of the Marker's object older than the given version.
+		VersionMarker string
+	}
+
+	// A ContainerEntry is a container or a subdir of a listing.
+	ContainerEntry struct {
+		Container *model.Container
+		Subdir    string
+	}
+
+	// An ObjectEntry is an object or a subdir of a listing.
+	ObjectEntry struct {
+		Object *model.Object
+		Subdir string
+	}
+
+	// A VersionEntry is a version of an object or a subdir of a versions listing.
+	VersionEntry struct {
+		Version *model.Version
+		// Latest is true for the latest version of the object, the current version or a delete marker.
+		Latest bool
+		Subdir string
+	}
+
 	MetaInteraction interface {
 		AddMeta(cid, okey string, key string, value string) (*model.Meta, error)
 		FindMeta(cid, okey string) ([]*model.Meta, error)
 		DeleteMeta(cid, okey string, key string) (error)
 		DeleteAllMetas(cid, okey string) (error)
+		// UpsertMetas sets the given metadata and keeps the other ones, an empty value deletes the metadata.
+		UpsertMetas(cid, okey string, metas map[string]string) error
+		// ReplaceMetas replaces all the metadata by the given ones, the empty values are ignored.
+		ReplaceMetas(cid, okey string, metas map[string]string) error
 	}
 )
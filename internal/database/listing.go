@@ -0,0 +1,152 @@
+package database
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mdouchement/openstackswift/internal/model"
+)
+
+// listingPageSize is the number of records fetched at once by a listing.
+const listingPageSize = 1000
+
+// A listingQuery defines the bounds of a listing's page.
+type listingQuery struct {
+	Prefix    string
+	Marker    string
+	EndMarker string
+	Reverse   bool
+	Limit     int
+}
+
+// list walks the records matching the options.
+// The fetch function returns a page of the records' names sorted according to the query; the yield function is
+// called with the index of a listed record in the last page, or -1 and the subdir rolling up several records,
+// and returns the number of entries it has listed.
+//
+// The records of a subdir are skipped in the fetched page, the next page starts after all of its records so the
+// remaining ones are never fetched.
+func list(opts ListOptions, fetch func(listingQuery) ([]string, error), yield func(index int, subdir string) int) error {
+	prefix, delimiter := opts.Prefix, opts.Delimiter
+	if opts.Path != "" {
+		prefix = strings.TrimSuffix(opts.Path, "/") + "/"
+		delimiter = "/"
+	}
+
+	query := listingQuery{
+		Prefix:    prefix,
+		Marker:    opts.Marker,
+		EndMarker: opts.EndMarker,
+		Reverse:   opts.Reverse,
+		Limit:     listingPageSize,
+	}
+
+	var count int
+	for {
+		names, err := fetch(query)
+		if err != nil {
+			return err
+		}
+
+		var skip string // The subdir whose records are skipped.
+		for i, name := range names {
+			if opts.Limit > 0 && count >= opts.Limit {
+				return nil
+			}
+			query.Marker = name
+
+			if skip != "" && strings.HasPrefix(name, skip) {
+				continue
+			}
+
+			if delimiter == "" {
+				count += yield(i, "")
+				continue
+			}
+
+			if opts.Path != "" && name == prefix {
+				continue // The path's pseudo-directory marker itself.
+			}
+
+			end := strings.Index(name[len(prefix):], delimiter)
+			if end < 0 {
+				count += yield(i, "")
+				continue
+			}
+
+			subdir := name[:len(prefix)+end+len(delimiter)]
+			if opts.Path != "" && name == subdir {
+				count += yield(i, "") // A pseudo-directory marker directly under the path.
+				continue
+			}
+			if opts.Path == "" && subdir != opts.Marker {
+				count += yield(-1, subdir)
+			}
+			skip = subdir
+		}
+
+		if len(names) < query.Limit {
+			return nil
+		}
+
+		if skip != "" && strings.HasPrefix(query.Marker, skip) {
+			// Skip the subdir's remaining records, no valid UTF-8 name is greater than the subdir followed by 0xFF.
+			query.Marker = skip + "\xff"
+			if opts.Reverse {
+				query.Marker = skip
+			}
+		}
+	}
+}
+
+// mergeKeys merges the pages of keys sorted according to the query into the distinct keys, sorted the same way.
+// When a page is full, the following keys may be missing from the other pages: the keys are cut after the last key
+// of the full pages, returned as the bound, and complete is false.
+func mergeKeys(query listingQuery, pages ...[]string) (keys []string, bound string, complete bool) {
+	complete = true
+	for _, page := range pages {
+		keys = append(keys, page...)
+
+		if len(page) < query.Limit {
+			continue
+		}
+		last := page[len(page)-1]
+		if complete || query.Reverse && last > bound || !query.Reverse && last < bound {
+			bound = last
+		}
+		complete = false
+	}
+
+	sort.Strings(keys)
+	keys = slices.Compact(keys)
+	if query.Reverse {
+		slices.Reverse(keys)
+	}
+
+	if !complete {
+		n := 0
+		for n < len(keys) && keys[n] != bound {
+			n++
+		}
+		keys = keys[:n+1]
+	}
+	return keys, bound, complete
+}
+
+// versionEntries returns the versions listing of a key, the current object, nil when it does not exist, followed by its
+// previous versions from the newest.
+func versionEntries(object *model.Object, versions []*model.Version) []*VersionEntry {
+	entries := make([]*VersionEntry, 0, len(versions)+1)
+	if object != nil {
+		entries = append(entries, &VersionEntry{Version: object.Version(), Latest: true})
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].LastModified.After(versions[j].LastModified)
+	})
+	for _, version := range versions {
+		entries = append(entries, &VersionEntry{Version: version, Latest: len(entries) == 0})
+	}
+	return entries
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RegisterUser creates or updates the user identified by its domain and name.
+// The domain and the project are created if they do not exist.
+func RegisterUser(db Client, domainname, projectname, username, password string) (*model.User, error) {
+	domain, err := db.FindDomainByName(domainname)
+	if err != nil && !db.IsNotFound(err) {
+		return nil, errors.Wrap(err, "RegisterUser")
+	}
+	if db.IsNotFound(err) {
+		domain = &model.Domain{Name: domainname}
+		if err = db.Save(domain); err != nil {
+			return nil, errors.Wrap(err, "RegisterUser domain")
+		}
+	}
+
+	//
+
+	project, err := db.FindProjectByName(domain.ID, projectname)
+	if err != nil && !db.IsNotFound(err) {
+		return nil, errors.Wrap(err, "RegisterUser")
+	}
+	if db.IsNotFound(err) {
+		project = &model.Project{DomainID: domain.ID, Name: projectname}
+		if err = db.Save(project); err != nil {
+			return nil, errors.Wrap(err, "RegisterUser project")
+		}
+	}
+
+	//
+
+	user, err := db.FindUserByName(domain.ID, username)
+	if err != nil && !db.IsNotFound(err) {
+		return nil, errors.Wrap(err, "RegisterUser")
+	}
+	if db.IsNotFound(err) {
+		user = &model.User{DomainID: domain.ID, Name: username}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.Wrap(err, "RegisterUser password")
+	}
+	user.ProjectID = project.ID
+	user.Password = string(hash)
+
+	err = db.Save(user)
+	return user, errors.Wrap(err, "RegisterUser user")
+}
+
+// CheckPassword returns true if the password matches the user's one.
+func CheckPassword(user *model.User, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
+}
+
+// RegisterApplicationCredential creates a new application credential for the user.
+// A random secret is generated when the given one is empty.
+func RegisterApplicationCredential(db Client, user *model.User, credential *model.ApplicationCredential, secret string) (string, error) {
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", errors.Wrap(err, "RegisterApplicationCredential")
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.Wrap(err, "RegisterApplicationCredential secret")
+	}
+	credential.UserID = user.ID
+	credential.Secret = string(hash)
+
+	err = db.Save(credential)
+	return secret, errors.Wrap(err, "RegisterApplicationCredential")
+}
+
+// CheckSecret returns true if the secret matches the application credential's one.
+func CheckSecret(credential *model.ApplicationCredential, secret string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(credential.Secret), []byte(secret)) == nil
+}
+
+// IssueToken creates a new random token for the user, valid until the given date.
+func IssueToken(db Client, user *model.User, expiresAt time.Time, methods ...string) (*model.Token, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, "IssueToken")
+	}
+
+	token := &model.Token{
+		Key:       hex.EncodeToString(key),
+		UserID:    user.ID,
+		ProjectID: user.ProjectID,
+		Methods:   methods,
+		ExpiresAt: expiresAt,
+	}
+
+	err := db.Save(token)
+	return token, errors.Wrap(err, "IssueToken")
+}
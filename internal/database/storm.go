@@ -2,6 +2,7 @@ package database
 
 import (
 	"regexp"
+	"sort"
 	"time"
 
 	"github.com/asdine/storm/v3"
@@ -25,6 +26,27 @@ func StormInit(database string) error {
 	if err != nil {
 		return errors.Wrap(err, "could not get database connection")
 	}
+	defer db.Close()
+
+	if err := db.Init(&model.Domain{}); err != nil {
+		return errors.Wrap(err, "could not init domain index")
+	}
+
+	if err := db.Init(&model.Project{}); err != nil {
+		return errors.Wrap(err, "could not init project index")
+	}
+
+	if err := db.Init(&model.User{}); err != nil {
+		return errors.Wrap(err, "could not init user index")
+	}
+
+	if err := db.Init(&model.Token{}); err != nil {
+		return errors.Wrap(err, "could not init token index")
+	}
+
+	if err := db.Init(&model.ApplicationCredential{}); err != nil {
+		return errors.Wrap(err, "could not init application credential index")
+	}
 
 	if err := db.Init(&model.Container{}); err != nil {
 		return errors.Wrap(err, "could not init container index")
@@ -34,8 +56,12 @@ func StormInit(database string) error {
 		return errors.Wrap(err, "could not init manifest index")
 	}
 
-	err = db.Init(&model.Object{})
-	return errors.Wrap(err, "could not init object index")
+	if err := db.Init(&model.Object{}); err != nil {
+		return errors.Wrap(err, "could not init object index")
+	}
+
+	err = db.Init(&model.Version{})
+	return errors.Wrap(err, "could not init version index")
 }
 
 func StormReIndex(database string) error {
@@ -43,6 +69,27 @@ func StormReIndex(database string) error {
 	if err != nil {
 		return errors.Wrap(err, "could not get database connection")
 	}
+	defer db.Close()
+
+	if err := db.ReIndex(&model.Domain{}); err != nil {
+		return errors.Wrap(err, "could not ReIndex domains")
+	}
+
+	if err := db.ReIndex(&model.Project{}); err != nil {
+		return errors.Wrap(err, "could not ReIndex projects")
+	}
+
+	if err := db.ReIndex(&model.User{}); err != nil {
+		return errors.Wrap(err, "could not ReIndex users")
+	}
+
+	if err := db.ReIndex(&model.Token{}); err != nil {
+		return errors.Wrap(err, "could not ReIndex tokens")
+	}
+
+	if err := db.ReIndex(&model.ApplicationCredential{}); err != nil {
+		return errors.Wrap(err, "could not ReIndex application credentials")
+	}
 
 	if err := db.ReIndex(&model.Container{}); err != nil {
 		return errors.Wrap(err, "could not ReIndex containers")
@@ -52,8 +99,79 @@ func StormReIndex(database string) error {
 		return errors.Wrap(err, "could not ReIndex manifests")
 	}
 
-	err = db.ReIndex(&model.Object{})
-	return errors.Wrap(err, "could not ReIndex objects")
+	if err := db.ReIndex(&model.Object{}); err != nil {
+		return errors.Wrap(err, "could not ReIndex objects")
+	}
+
+	if err := db.ReIndex(&model.Version{}); err != nil {
+		return errors.Wrap(err, "could not ReIndex versions")
+	}
+
+	return errors.Wrap(recount(db), "could not recount containers")
+}
+
+// migrationsBucket is the bucket recording the applied migrations.
+const migrationsBucket = "migrations"
+
+// migrations are the data migrations applied once, in order, when the database is opened.
+var migrations = []struct {
+	name string
+	run  func(db *storm.DB) error
+}{
+	{name: "container-usage", run: recount},
+}
+
+// migrate applies the migrations not yet recorded in the database.
+func migrate(db *storm.DB) error {
+	for _, migration := range migrations {
+		var applied bool
+		err := db.Get(migrationsBucket, migration.name, &applied)
+		if err != nil && err != storm.ErrNotFound {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		if err = migration.run(db); err != nil {
+			return errors.Wrap(err, migration.name)
+		}
+		if err = db.Set(migrationsBucket, migration.name, true); err != nil {
+			return errors.Wrap(err, migration.name)
+		}
+	}
+	return nil
+}
+
+// recount computes again the object count and bytes used of all the containers from their objects.
+func recount(db *storm.DB) error {
+	tx, err := db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var containers []*model.Container
+	if err = tx.All(&containers); err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		var objects []*model.Object
+		err = tx.Find("ContainerID", container.ID, &objects)
+		if err != nil && err != storm.ErrNotFound {
+			return err
+		}
+
+		container.Count, container.Bytes = len(objects), 0
+		for _, object := range objects {
+			container.Bytes += object.Size
+		}
+		if err = tx.Save(container); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func StormOpen(database string) (Client, error) {
@@ -62,12 +180,27 @@ func StormOpen(database string) (Client, error) {
 		return nil, errors.Wrap(err, "could not get database connection")
 	}
 
+	if err = migrate(db); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "could not migrate database")
+	}
+
 	return &strm{
 		db: db,
 	}, nil
 }
 
 func (c *strm) Save(m model.Model) error {
+	stamp(m)
+
+	if object, ok := m.(*model.Object); ok {
+		return errors.Wrap(c.saveObject(object), "could not save the model")
+	}
+	return errors.Wrap(c.db.Save(m), "could not save the model")
+}
+
+// stamp sets the model's update date and, for a new model, its ID and creation date.
+func stamp(m model.Model) {
 	t := time.Now().UTC()
 	m.SetUpdatedAt(t)
 
@@ -75,11 +208,12 @@ func (c *strm) Save(m model.Model) error {
 		m.SetID(uuid.Must(uuid.NewV4()).String())
 		m.SetCreatedAt(t)
 	}
-
-	return errors.Wrap(c.db.Save(m), "could not save the model")
 }
 
 func (c *strm) Delete(m model.Model) error {
+	if object, ok := m.(*model.Object); ok {
+		return errors.Wrap(c.deleteObject(object.ID), "could not delete the model")
+	}
 	return errors.Wrap(c.db.DeleteStruct(m), "could not delete the model")
 }
 
@@ -91,25 +225,183 @@ func (c *strm) IsNotFound(err error) bool {
 	return errors.Cause(err) == storm.ErrNotFound
 }
 
+//
+// Domain
+//
+
+func (c *strm) FindDomain(id string) (*model.Domain, error) {
+	var domain model.Domain
+	err := c.db.One("ID", id, &domain)
+	return &domain, errors.Wrap(err, "could not find domain")
+}
+
+func (c *strm) FindDomainByName(name string) (*model.Domain, error) {
+	var domain model.Domain
+	err := c.db.One("Name", name, &domain)
+	return &domain, errors.Wrap(err, "could not find domain")
+}
+
+//
+// Project
+//
+
+func (c *strm) FindProject(id string) (*model.Project, error) {
+	var project model.Project
+	err := c.db.One("ID", id, &project)
+	return &project, errors.Wrap(err, "could not find project")
+}
+
+func (c *strm) FindProjectByName(did, name string) (*model.Project, error) {
+	var project model.Project
+	err := c.db.Select(q.Eq("DomainID", did), q.Eq("Name", name)).First(&project)
+	return &project, errors.Wrap(err, "could not find project")
+}
+
+//
+// User
+//
+
+func (c *strm) ListUsers() ([]*model.User, error) {
+	users := make([]*model.User, 0)
+	err := c.db.AllByIndex("Name", &users)
+	return users, errors.Wrap(err, "could not get all users")
+}
+
+func (c *strm) FindUser(id string) (*model.User, error) {
+	var user model.User
+	err := c.db.One("ID", id, &user)
+	return &user, errors.Wrap(err, "could not find user")
+}
+
+func (c *strm) FindUserByName(did, name string) (*model.User, error) {
+	var user model.User
+	err := c.db.Select(q.Eq("DomainID", did), q.Eq("Name", name)).First(&user)
+	return &user, errors.Wrap(err, "could not find user")
+}
+
+func (c *strm) DeleteUser(id string) error {
+	err := c.db.Select(q.Eq("ID", id)).Delete(&model.User{})
+	return errors.Wrap(err, "could not delete user")
+}
+
+//
+// Token
+//
+
+func (c *strm) FindTokenByKey(key string) (*model.Token, error) {
+	var token model.Token
+	err := c.db.One("Key", key, &token)
+	return &token, errors.Wrap(err, "could not find token")
+}
+
+func (c *strm) DeleteToken(id string) error {
+	err := c.db.Select(q.Eq("ID", id)).Delete(&model.Token{})
+	return errors.Wrap(err, "could not delete token")
+}
+
+func (c *strm) DeleteTokensByUserID(uid string) error {
+	err := c.db.Select(q.Eq("UserID", uid)).Delete(&model.Token{})
+	if c.IsNotFound(err) {
+		err = nil
+	}
+	return errors.Wrap(err, "could not delete tokens")
+}
+
+func (c *strm) DeleteExpiredTokens() error {
+	err := c.db.Select(q.Lte("ExpiresAt", time.Now())).Delete(&model.Token{})
+	if c.IsNotFound(err) {
+		err = nil
+	}
+	return errors.Wrap(err, "could not delete expired tokens")
+}
+
+//
+// Application credential
+//
+
+func (c *strm) ListApplicationCredentials(uid string) ([]*model.ApplicationCredential, error) {
+	credentials := make([]*model.ApplicationCredential, 0)
+	err := c.db.Select(q.Eq("UserID", uid)).OrderBy("Name").Find(&credentials)
+	if c.IsNotFound(err) {
+		err = nil
+	}
+	return credentials, errors.Wrap(err, "could not get application credentials")
+}
+
+func (c *strm) FindApplicationCredential(id string) (*model.ApplicationCredential, error) {
+	var credential model.ApplicationCredential
+	err := c.db.One("ID", id, &credential)
+	return &credential, errors.Wrap(err, "could not find application credential")
+}
+
+func (c *strm) FindApplicationCredentialByName(uid, name string) (*model.ApplicationCredential, error) {
+	var credential model.ApplicationCredential
+	err := c.db.Select(q.Eq("UserID", uid), q.Eq("Name", name)).First(&credential)
+	return &credential, errors.Wrap(err, "could not find application credential")
+}
+
+func (c *strm) DeleteApplicationCredential(id string) error {
+	err := c.db.Select(q.Eq("ID", id)).Delete(&model.ApplicationCredential{})
+	return errors.Wrap(err, "could not delete application credential")
+}
+
+func (c *strm) DeleteApplicationCredentialsByUserID(uid string) error {
+	err := c.db.Select(q.Eq("UserID", uid)).Delete(&model.ApplicationCredential{})
+	if c.IsNotFound(err) {
+		err = nil
+	}
+	return errors.Wrap(err, "could not delete application credentials")
+}
+
 //
 // Container
 //
 
-func (c *strm) ListContainers() ([]*model.Container, error) {
+func (c *strm) ListContainers(pid string) ([]*model.Container, error) {
 	containers := make([]*model.Container, 0)
-	err := c.db.AllByIndex("Name", &containers)
+	err := c.db.Select(q.Eq("ProjectID", pid)).OrderBy("Name").Find(&containers)
+	if c.IsNotFound(err) {
+		err = nil
+	}
 	return containers, errors.Wrap(err, "could not get all containers")
 }
 
+func (c *strm) ListContainerEntries(pid string, opts ListOptions) ([]*ContainerEntry, error) {
+	entries := make([]*ContainerEntry, 0)
+
+	var containers []*model.Container
+	err := list(opts, func(query listingQuery) ([]string, error) {
+		containers = make([]*model.Container, 0)
+		err := c.listing(q.Eq("ProjectID", pid), "Name", query).Find(&containers)
+		if err != nil && !c.IsNotFound(err) {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(containers))
+		for _, container := range containers {
+			names = append(names, container.Name)
+		}
+		return names, nil
+	}, func(i int, subdir string) int {
+		if i < 0 {
+			entries = append(entries, &ContainerEntry{Subdir: subdir})
+			return 1
+		}
+		entries = append(entries, &ContainerEntry{Container: containers[i]})
+		return 1
+	})
+	return entries, errors.Wrap(err, "could not list containers")
+}
+
 func (c *strm) FindContainer(id string) (*model.Container, error) {
 	var container model.Container
 	err := c.db.One("ID", id, &container)
 	return &container, errors.Wrap(err, "could not find container")
 }
 
-func (c *strm) FindContainerByName(name string) (*model.Container, error) {
+func (c *strm) FindContainerByName(pid, name string) (*model.Container, error) {
 	var container model.Container
-	err := c.db.One("Name", name, &container)
+	err := c.db.Select(q.Eq("ProjectID", pid), q.Eq("Name", name)).First(&container)
 	return &container, errors.Wrap(err, "could not find container")
 }
 
@@ -122,6 +414,33 @@ func (c *strm) DeleteContainer(id string) error {
 // Object
 //
 
+func (c *strm) ListObjectEntries(cid string, opts ListOptions) ([]*ObjectEntry, error) {
+	entries := make([]*ObjectEntry, 0)
+
+	var objects []*model.Object
+	err := list(opts, func(query listingQuery) ([]string, error) {
+		objects = make([]*model.Object, 0)
+		err := c.listing(q.Eq("ContainerID", cid), "Key", query).Find(&objects)
+		if err != nil && !c.IsNotFound(err) {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(objects))
+		for _, object := range objects {
+			names = append(names, object.Key)
+		}
+		return names, nil
+	}, func(i int, subdir string) int {
+		if i < 0 {
+			entries = append(entries, &ObjectEntry{Subdir: subdir})
+			return 1
+		}
+		entries = append(entries, &ObjectEntry{Object: objects[i]})
+		return 1
+	})
+	return entries, errors.Wrap(err, "could not list objects")
+}
+
 func (c *strm) AllObjects() ([]*model.Object, error) {
 	objects := make([]*model.Object, 0)
 	err := c.db.All(&objects)
@@ -152,8 +471,73 @@ func (c *strm) FindObjectByKey(cid, key string) (*model.Object, error) {
 }
 
 func (c *strm) DeleteObject(id string) error {
-	err := c.db.Select(q.Eq("ID", id)).Delete(&model.Object{})
-	return errors.Wrap(err, "could not delete object")
+	return errors.Wrap(c.deleteObject(id), "could not delete object")
+}
+
+// saveObject saves the object and updates the object count and bytes used of its container in the same transaction.
+func (c *strm) saveObject(object *model.Object) error {
+	tx, err := c.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previous model.Object
+	err = tx.One("ID", object.ID, &previous)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+	if err == nil {
+		if err = count(tx, previous.ContainerID, -1, -previous.Size); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Save(object); err != nil {
+		return err
+	}
+	if err = count(tx, object.ContainerID, 1, object.Size); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteObject deletes the object and updates the object count and bytes used of its container in the same transaction.
+func (c *strm) deleteObject(id string) error {
+	tx, err := c.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var object model.Object
+	if err = tx.One("ID", id, &object); err != nil {
+		return err
+	}
+
+	if err = tx.DeleteStruct(&object); err != nil {
+		return err
+	}
+	if err = count(tx, object.ContainerID, -1, -object.Size); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// count adds the given number of objects and bytes to the container's usage, a missing container is ignored.
+func count(tx storm.Node, cid string, objects int, bytes int64) error {
+	var container model.Container
+	err := tx.One("ID", cid, &container)
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	container.Count += objects
+	container.Bytes += bytes
+	return tx.Save(&container)
 }
 
 //
@@ -171,6 +555,170 @@ func (c *strm) DeleteManifest(id string) error {
 	return errors.Wrap(err, "could not delete manifest")
 }
 
+//
+// Version
+//
+
+func (c *strm) FindVersions(cid, prefix string) ([]*model.Version, error) {
+	versions := make([]*model.Version, 0)
+	err := c.db.Select(q.Eq("ContainerID", cid), q.Re("Key", "^"+regexp.QuoteMeta(prefix))).Find(&versions)
+	if c.IsNotFound(err) {
+		err = nil
+	}
+	if err != nil {
+		return versions, errors.Wrap(err, "could not get versions")
+	}
+
+	// Keys in ascending order, versions from the newest.
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].Key != versions[j].Key {
+			return versions[i].Key < versions[j].Key
+		}
+		return versions[i].LastModified.After(versions[j].LastModified)
+	})
+	return versions, nil
+}
+
+func (c *strm) FindVersion(cid, key, vid string) (*model.Version, error) {
+	var version model.Version
+	err := c.db.Select(q.Eq("ContainerID", cid), q.Eq("Key", key), q.Eq("VersionID", vid)).First(&version)
+	return &version, errors.Wrap(err, "could not find version")
+}
+
+func (c *strm) ListVersionEntries(cid string, opts ListOptions) ([]*VersionEntry, error) {
+	entries := make([]*VersionEntry, 0)
+
+	// The versions of the Marker's object older than the VersionMarker are listed first.
+	if opts.Marker != "" && opts.VersionMarker != "" {
+		page, err := c.versionPage(cid, []string{opts.Marker})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not list versions")
+		}
+
+		listed := false
+		for _, entry := range page[opts.Marker] {
+			if listed && (opts.Limit == 0 || len(entries) < opts.Limit) {
+				entries = append(entries, entry)
+			}
+			listed = listed || entry.Version.VersionID == opts.VersionMarker
+		}
+
+		if opts.Limit > 0 {
+			if opts.Limit <= len(entries) {
+				return entries, nil
+			}
+			opts.Limit -= len(entries)
+		}
+	}
+	limit := opts.Limit + len(entries)
+
+	var keys []string
+	var page map[string][]*VersionEntry
+	err := list(opts, func(query listingQuery) ([]string, error) {
+		var err error
+		if keys, err = c.versionKeys(cid, query); err != nil {
+			return nil, err
+		}
+		page, err = c.versionPage(cid, keys)
+		return keys, err
+	}, func(i int, subdir string) int {
+		if i < 0 {
+			entries = append(entries, &VersionEntry{Subdir: subdir})
+			return 1
+		}
+
+		n := 0
+		for _, entry := range page[keys[i]] {
+			if opts.Limit > 0 && len(entries) >= limit {
+				break
+			}
+			entries = append(entries, entry)
+			n++
+		}
+		return n
+	})
+	return entries, errors.Wrap(err, "could not list versions")
+}
+
+// versionKeys returns a page of the distinct keys of the container's objects and versions.
+func (c *strm) versionKeys(cid string, query listingQuery) ([]string, error) {
+	var keys []string
+	for len(keys) < query.Limit {
+		objects := make([]*model.Object, 0)
+		err := c.listing(q.Eq("ContainerID", cid), "Key", query).Find(&objects)
+		if err != nil && !c.IsNotFound(err) {
+			return nil, err
+		}
+
+		versions := make([]*model.Version, 0)
+		err = c.listing(q.Eq("ContainerID", cid), "Key", query).Find(&versions)
+		if err != nil && !c.IsNotFound(err) {
+			return nil, err
+		}
+
+		okeys := make([]string, 0, len(objects))
+		for _, object := range objects {
+			okeys = append(okeys, object.Key)
+		}
+		vkeys := make([]string, 0, len(versions))
+		for _, version := range versions {
+			vkeys = append(vkeys, version.Key)
+		}
+
+		merged, bound, complete := mergeKeys(query, okeys, vkeys)
+		keys = append(keys, merged...)
+		if complete {
+			break
+		}
+		query.Marker = bound
+	}
+
+	if len(keys) > query.Limit {
+		keys = keys[:query.Limit]
+	}
+	return keys, nil
+}
+
+// versionPage returns the versions listing of each of the given sorted keys.
+func (c *strm) versionPage(cid string, keys []string) (map[string][]*VersionEntry, error) {
+	page := map[string][]*VersionEntry{}
+	if len(keys) == 0 {
+		return page, nil
+	}
+	lower, upper := min(keys[0], keys[len(keys)-1]), max(keys[0], keys[len(keys)-1])
+
+	objects := make([]*model.Object, 0)
+	err := c.db.Select(q.Eq("ContainerID", cid), q.Gte("Key", lower), q.Lte("Key", upper)).Find(&objects)
+	if err != nil && !c.IsNotFound(err) {
+		return nil, err
+	}
+
+	versions := make([]*model.Version, 0)
+	err = c.db.Select(q.Eq("ContainerID", cid), q.Gte("Key", lower), q.Lte("Key", upper)).Find(&versions)
+	if err != nil && !c.IsNotFound(err) {
+		return nil, err
+	}
+
+	current := make(map[string]*model.Object, len(objects))
+	for _, object := range objects {
+		current[object.Key] = object
+	}
+	previous := map[string][]*model.Version{}
+	for _, version := range versions {
+		previous[version.Key] = append(previous[version.Key], version)
+	}
+
+	for _, key := range keys {
+		page[key] = versionEntries(current[key], previous[key])
+	}
+	return page, nil
+}
+
+func (c *strm) DeleteVersion(id string) error {
+	err := c.db.Select(q.Eq("ID", id)).Delete(&model.Version{})
+	return errors.Wrap(err, "could not delete version")
+}
+
 //
 // Meta
 //
@@ -201,3 +749,84 @@ func (c *strm) DeleteAllMetas(cid, okey string) (error) {
 	err := c.db.Select(q.Eq("ContainerID", cid), q.Eq("ObjectKey", okey)).Delete(&model.Meta{})
 	return errors.Wrap(err, "could not delete all metas")
 }
+
+func (c *strm) UpsertMetas(cid, okey string, metas map[string]string) error {
+	tx, err := c.db.Begin(true)
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer tx.Rollback()
+
+	for key, value := range metas {
+		err = tx.Select(q.Eq("ContainerID", cid), q.Eq("ObjectKey", okey), q.Eq("Key", key)).Delete(&model.Meta{})
+		if err != nil && err != storm.ErrNotFound {
+			return errors.Wrap(err, "could not delete meta")
+		}
+		if err = saveMeta(tx, cid, okey, key, value); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "could not upsert metas")
+}
+
+func (c *strm) ReplaceMetas(cid, okey string, metas map[string]string) error {
+	tx, err := c.db.Begin(true)
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer tx.Rollback()
+
+	err = tx.Select(q.Eq("ContainerID", cid), q.Eq("ObjectKey", okey)).Delete(&model.Meta{})
+	if err != nil && err != storm.ErrNotFound {
+		return errors.Wrap(err, "could not delete all metas")
+	}
+	for key, value := range metas {
+		if err = saveMeta(tx, cid, okey, key, value); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrap(tx.Commit(), "could not replace metas")
+}
+
+// saveMeta saves the metadata in the transaction, an empty value is not saved.
+func saveMeta(tx storm.Node, cid, okey, key, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	meta := &model.Meta{ContainerID: cid, ObjectKey: okey, Key: key, Value: value}
+	stamp(meta)
+	return errors.Wrap(tx.Save(meta), "could not save meta")
+}
+
+//
+// Listing
+//
+
+// listing returns the query selecting a page of the records owned by the given matcher and sorted by the given field.
+func (c *strm) listing(owner q.Matcher, field string, query listingQuery) storm.Query {
+	matchers := []q.Matcher{owner}
+	if query.Prefix != "" {
+		matchers = append(matchers, q.Re(field, "^"+regexp.QuoteMeta(query.Prefix)))
+	}
+
+	if query.Reverse {
+		if query.Marker != "" {
+			matchers = append(matchers, q.Lt(field, query.Marker))
+		}
+		if query.EndMarker != "" {
+			matchers = append(matchers, q.Gt(field, query.EndMarker))
+		}
+		return c.db.Select(matchers...).OrderBy(field).Reverse().Limit(query.Limit)
+	}
+
+	if query.Marker != "" {
+		matchers = append(matchers, q.Gt(field, query.Marker))
+	}
+	if query.EndMarker != "" {
+		matchers = append(matchers, q.Lt(field, query.EndMarker))
+	}
+	return c.db.Select(matchers...).OrderBy(field).Limit(query.Limit)
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// An ApplicationCredential allows an application to authenticate as a User without its password.
+type ApplicationCredential struct {
+	Base `json:",inline" storm:"inline"`
+
+	UserID      string    `json:"user_id"     storm:"index"`
+	Name        string    `json:"name"        storm:"index"`
+	Description string    `json:"description"`
+	Secret      string    `json:"secret"` // bcrypt hash
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Expired returns true if the credential can no longer be used.
+func (m *ApplicationCredential) Expired() bool {
+	return !m.ExpiresAt.IsZero() && !m.ExpiresAt.After(time.Now())
+}
@@ -1,10 +1,23 @@
 package model
 
+import "path"
+
 // A Container holds several Objects and Manifests.
 type Container struct {
 	Base `json:",inline" storm:"inline"`
 
-	Name   string `json:"name" storm:"unique"`
-	Count  int    `json:"count"`
-	Bytes  int64  `json:"bytes"`
+	ProjectID string `json:"project_id" storm:"index"`
+	Name      string `json:"name"       storm:"index"`
+	Count     int    `json:"count"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// Path returns the location of the container in the storage backend.
+func (m *Container) Path() string {
+	return path.Join(m.ProjectID, m.Name)
+}
+
+// VersionsPath returns the location of the container's previous object versions in the storage backend.
+func (m *Container) VersionsPath() string {
+	return path.Join(".versions", m.ProjectID, m.ID)
 }
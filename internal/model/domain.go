@@ -0,0 +1,8 @@
+package model
+
+// A Domain is a Keystone namespace for Projects and Users.
+type Domain struct {
+	Base `json:",inline" storm:"inline"`
+
+	Name string `json:"name" storm:"unique"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // A Manifest represents aggregates an blob across several Objects used by chunked upload.
 type Manifest struct {
 	Base `json:",inline" storm:"inline"`
@@ -12,4 +14,33 @@ type Manifest struct {
 	ContentType string `json:"content_type"`
 	// FilePath    string `json:"file_path"`
 	Checksum string `json:"checksum"`
+
+	// Prefix is the `container/prefix' of a dynamic large object's segments, they are resolved at read time.
+	Prefix string `json:"prefix"`
+
+	// Static is true for a static large object, its content is the concatenation of the listed Segments.
+	Static   bool       `json:"static"`
+	Segments []*Segment `json:"segments,omitempty"`
+	// Depth is the nesting level of a static large object, 1 when none of its segments is a static large object.
+	Depth int `json:"depth,omitempty"`
+}
+
+// A Segment is a part of a static large object, it references an object or a nested static large object.
+type Segment struct {
+	Container    string    `json:"container"`
+	Key          string    `json:"key"`
+	Checksum     string    `json:"checksum"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	LastModified time.Time `json:"last_modified"`
+
+	// Range is the range of bytes used by the static large object, the whole segment when empty.
+	Range  string `json:"range,omitempty"`
+	Offset int64  `json:"offset"`
+	Length int64  `json:"length"`
+}
+
+// Path returns the segment's path in the account.
+func (s *Segment) Path() string {
+	return "/" + s.Container + "/" + s.Key
 }
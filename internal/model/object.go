@@ -14,4 +14,20 @@ type Object struct {
 	ContentType string    `json:"content_type"`
 	Checksum    string    `json:"checksum"`
 	TTL         time.Time `json:"ttl"          storm:"index"`
+	VersionID   string    `json:"version_id"`
+
+	// The target of a symlink, a static symlink also has the Etag of its target.
+	SymlinkTarget  string `json:"symlink_target"`
+	SymlinkAccount string `json:"symlink_account"`
+	SymlinkEtag    string `json:"symlink_etag"`
+}
+
+// IsSymlink returns true when the object is a symlink to another object.
+func (m *Object) IsSymlink() bool {
+	return m.SymlinkTarget != ""
+}
+
+// SymlinkPath returns the path of the symlink's target.
+func (m *Object) SymlinkPath() string {
+	return "/v1/" + m.SymlinkAccount + "/" + m.SymlinkTarget
 }
@@ -0,0 +1,14 @@
+package model
+
+// A Project is a Keystone tenant, exposed as a Swift account.
+type Project struct {
+	Base `json:",inline" storm:"inline"`
+
+	DomainID string `json:"domain_id" storm:"index"`
+	Name     string `json:"name"      storm:"index"`
+}
+
+// Account returns the Swift account name of the project.
+func (m *Project) Account() string {
+	return "AUTH_" + m.ID
+}
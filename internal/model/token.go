@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// A Token is an authentication token issued to a User and scoped to a Project.
+type Token struct {
+	Base `json:",inline" storm:"inline"`
+
+	Key       string    `json:"key"        storm:"unique"`
+	UserID    string    `json:"user_id"    storm:"index"`
+	ProjectID string    `json:"project_id" storm:"index"`
+	Methods   []string  `json:"methods"`
+	ExpiresAt time.Time `json:"expires_at" storm:"index"`
+}
+
+// Expired returns true if the token can no longer be used.
+func (m *Token) Expired() bool {
+	return !m.ExpiresAt.After(time.Now())
+}
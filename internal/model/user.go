@@ -0,0 +1,11 @@
+package model
+
+// A User is a Keystone identity allowed to access a Project.
+type User struct {
+	Base `json:",inline" storm:"inline"`
+
+	DomainID  string `json:"domain_id"  storm:"index"`
+	ProjectID string `json:"project_id" storm:"index"`
+	Name      string `json:"name"       storm:"index"`
+	Password  string `json:"password"` // bcrypt hash
+}
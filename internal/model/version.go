@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// NullVersionID is the version identifier of the objects created while the versioning was disabled.
+const NullVersionID = "null"
+
+// A Version is a previous version or a delete marker of an Object of a container with versioning enabled.
+type Version struct {
+	Base `json:",inline" storm:"inline"`
+
+	ContainerID string `json:"container_id" storm:"index"`
+	Key         string `json:"key"          storm:"index"`
+	VersionID   string `json:"version_id"   storm:"index"`
+
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type"`
+	Checksum     string    `json:"checksum"`
+	LastModified time.Time `json:"last_modified"`
+	DeleteMarker bool      `json:"delete_marker"`
+}
+
+// Object returns the object of the version.
+func (m *Version) Object() *Object {
+	object := &Object{
+		ContainerID: m.ContainerID,
+		Key:         m.Key,
+		Size:        m.Size,
+		ContentType: m.ContentType,
+		Checksum:    m.Checksum,
+		VersionID:   m.VersionID,
+	}
+	if object.VersionID == NullVersionID {
+		object.VersionID = ""
+	}
+	object.CreatedAt = &m.LastModified
+	object.UpdatedAt = &m.LastModified
+	return object
+}
+
+// Version returns the current version of the object.
+func (m *Object) Version() *Version {
+	version := &Version{
+		ContainerID: m.ContainerID,
+		Key:         m.Key,
+		VersionID:   m.VersionID,
+		Size:        m.Size,
+		ContentType: m.ContentType,
+		Checksum:    m.Checksum,
+	}
+	if version.VersionID == "" {
+		version.VersionID = NullVersionID
+	}
+	if m.UpdatedAt != nil {
+		version.LastModified = *m.UpdatedAt
+	}
+	return version
+}
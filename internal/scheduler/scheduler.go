@@ -27,56 +27,68 @@ func Start(c Controller) {
 	log := c.Logger.WithPrefix("[scheduler]")
 
 	_, err := cron.AddFunc(c.Specification, func() {
-		log = c.Logger.WithPrefix("[TTL]")
+		Run(c)
+	})
+	if err != nil {
+		panic(err)
+	}
+	log.Info("TTL object task registred")
+
+	cron.Start()
+	log.Info("Scheduler is running")
+}
+
+// Run removes the expired objects and tokens and cleans up the storage.
+func Run(c Controller) {
+	log := c.Logger.WithPrefix("[TTL]")
+
+	objects, err := c.Database.AllObjects()
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	for _, object := range objects {
+		if object.TTL.IsZero() {
+			continue
+		}
 
-		objects, err := c.Database.AllObjects()
+		if object.TTL.After(time.Now()) {
+			continue
+		}
+
+		container, err := c.Database.FindContainer(object.ContainerID)
 		if err != nil {
 			log.Error(err)
 			return
 		}
 
-		for _, object := range objects {
-			if object.TTL.IsZero() {
-				continue
-			}
-
-			if object.TTL.After(time.Now()) {
-				continue
-			}
-
-			container, err := c.Database.FindContainer(object.ContainerID)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-
-			err = c.Storage.Remove(container.Name, object.Key)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-
-			err = c.Database.Delete(object)
-			if err != nil {
-				log.Error(err)
-				return
-			}
-
-			log.Infof("Removed %s", path.Join(container.Name, object.Key))
+		err = c.Storage.Remove(container.Path(), object.Key)
+		if err != nil {
+			log.Error(err)
+			return
 		}
 
-		log.Info("Storage cleanup")
-		err = c.Storage.Cleanup()
+		err = c.Database.Delete(object)
 		if err != nil {
 			log.Error(err)
 			return
 		}
-	})
+
+		log.Infof("Removed %s", path.Join(container.Name, object.Key))
+	}
+
+	log.Info("Tokens cleanup")
+	err = c.Database.DeleteExpiredTokens()
 	if err != nil {
-		panic(err)
+		log.Error(err)
+		return
 	}
-	log.Info("TTL object task registred")
 
-	cron.Start()
-	log.Info("Scheduler is running")
+	log.Info("Storage cleanup")
+	err = c.Storage.Cleanup()
+	if err != nil {
+		log.Error(err)
+		return
+	}
 }
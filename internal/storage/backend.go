@@ -7,12 +7,14 @@ type Backend interface {
 	// Name returns the name of the backend implementation.
 	Name() string
 
-	// Reader returns a ReadCloser of the file.
-	Reader(container, object string) (io.ReadCloser, error)
-	// Reader returns a WriteCloser of the file.
-	Writer(container, object string) (io.WriteCloser, error)
+	// Reader returns a seekable ReadCloser of the file.
+	Reader(container, object string) (io.ReadSeekCloser, error)
+	// Writer returns a WriteCommitter of the file.
+	Writer(container, object string) (WriteCommitter, error)
 	// Copy copies a file.
 	Copy(sc, so, dc, do string) error
+	// Move renames the given folder.
+	Move(src, dst string) error
 
 	// FilenamesFrom list all the object names from the given prefix: `container/prefix'.
 	FilenamesFrom(prefix string) ([]string, error)
@@ -24,3 +26,15 @@ type Backend interface {
 	// Cleanup cleans useless artifacts in storage.
 	Cleanup() error
 }
+
+// A WriteCommitter writes a file that replaces the previous one only once committed.
+// Readers never see a partially written file.
+type WriteCommitter interface {
+	io.Writer
+
+	// Commit atomically replaces the file by the written one.
+	Commit() error
+	// Abort discards the written file and keeps the previous one.
+	// It does nothing once the file is committed.
+	Abort() error
+}
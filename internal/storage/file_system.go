@@ -6,10 +6,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
+const (
+	// tmpDirname is the directory of the workspace holding the files being written.
+	tmpDirname = ".tmp"
+	// tmpLifetime is the age above which a file being written is considered as abandoned.
+	tmpLifetime = 24 * time.Hour
+)
+
 type fs struct {
 	workspace string
 }
@@ -25,22 +33,30 @@ func (b *fs) Name() string {
 	return "file_system"
 }
 
-func (b *fs) Reader(container, object string) (io.ReadCloser, error) {
+func (b *fs) Reader(container, object string) (io.ReadSeekCloser, error) {
 	rc, err := os.Open(filepath.Join(b.workspace, container, object))
 	if err != nil {
-		return rc, errors.Wrap(err, "could not open file")
+		return nil, errors.Wrap(err, "could not open file")
 	}
 	return rc, err
 }
 
-func (b *fs) Writer(container, object string) (io.WriteCloser, error) {
-	b.mkdirAllWithFilename(container, object)
+// Writer writes the file in a temporary file of the workspace renamed on commit.
+func (b *fs) Writer(container, object string) (WriteCommitter, error) {
+	tmpdir := filepath.Join(b.workspace, tmpDirname)
+	if err := os.MkdirAll(tmpdir, 0755); err != nil {
+		return nil, errors.Wrap(err, "could not create temporary directory")
+	}
 
-	wc, err := os.Create(filepath.Join(b.workspace, container, object))
+	f, err := os.CreateTemp(tmpdir, "write-*")
 	if err != nil {
-		return wc, errors.Wrap(err, "could not create file")
+		return nil, errors.Wrap(err, "could not create file")
 	}
-	return wc, err
+
+	return &fswriter{
+		File:     f,
+		filename: filepath.Join(b.workspace, container, object),
+	}, nil
 }
 
 func (b *fs) Copy(sc, so, dc, do string) error {
@@ -52,13 +68,11 @@ func (b *fs) Copy(sc, so, dc, do string) error {
 
 	//
 
-	b.mkdirAllWithFilename(dc, do)
-
-	dst, err := os.Create(filepath.Join(b.workspace, dc, do))
+	dst, err := b.Writer(dc, do)
 	if err != nil {
 		return errors.Wrap(err, "copy: destination")
 	}
-	defer dst.Close()
+	defer dst.Abort()
 
 	//
 
@@ -67,10 +81,26 @@ func (b *fs) Copy(sc, so, dc, do string) error {
 		return errors.Wrap(err, "copy")
 	}
 
-	err = dst.Sync()
+	err = dst.Commit()
 	return errors.Wrap(err, "copy: destination")
 }
 
+// Move does nothing when the source folder does not exist.
+func (b *fs) Move(src, dst string) error {
+	src = filepath.Join(b.workspace, src)
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+
+	dst = filepath.Join(b.workspace, dst)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return errors.Wrap(err, "move: destination")
+	}
+
+	err := os.Rename(src, dst)
+	return errors.Wrap(err, "move")
+}
+
 func (b *fs) FilenamesFrom(prefix string) ([]string, error) {
 	entries, err := os.ReadDir(filepath.Join(b.workspace, prefix))
 	if err != nil {
@@ -121,6 +151,11 @@ func (b *fs) Cleanup() error {
 			return err
 		}
 
+		if info.IsDir() && path == filepath.Join(b.workspace, tmpDirname) {
+			b.cleanupTmp(path)
+			return filepath.SkipDir
+		}
+
 		if info.IsDir() {
 			if path == b.workspace {
 				return nil
@@ -159,12 +194,65 @@ func (b *fs) Cleanup() error {
 	return nil
 }
 
-func (b *fs) mkdirAllWithFilename(container, object string) {
-	b.mkdirAll(container, filepath.Dir(object))
+// cleanupTmp removes the temporary files left by interrupted writes.
+func (b *fs) cleanupTmp(tmpdir string) {
+	entries, err := os.ReadDir(tmpdir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < tmpLifetime {
+			continue
+		}
+		os.Remove(filepath.Join(tmpdir, entry.Name()))
+	}
+}
+
+//
+// Writer
+//
+
+type fswriter struct {
+	*os.File
+	filename string
+	done     bool
 }
 
-func (b *fs) mkdirAll(container, object string) {
-	if !b.Exist(container, object) {
-		os.MkdirAll(filepath.Join(b.workspace, container, object), 0755)
+func (w *fswriter) Commit() error {
+	if w.done {
+		return nil
+	}
+
+	if err := w.Sync(); err != nil {
+		w.Abort()
+		return errors.Wrap(err, "could not sync file")
 	}
+	if err := w.Close(); err != nil {
+		w.Abort()
+		return errors.Wrap(err, "could not close file")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		w.Abort()
+		return errors.Wrap(err, "could not create directory")
+	}
+	if err := os.Rename(w.Name(), w.filename); err != nil {
+		w.Abort()
+		return errors.Wrap(err, "could not commit file")
+	}
+
+	w.done = true
+	return nil
+}
+
+func (w *fswriter) Abort() error {
+	if w.done {
+		return nil
+	}
+	w.done = true
+
+	w.Close()
+	return errors.Wrap(os.Remove(w.Name()), "could not abort file")
 }
@@ -0,0 +1,75 @@
+package webserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/logger"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+)
+
+type account struct {
+	logger logger.Logger
+	db     database.Client
+}
+
+// Show renders the account's metadata and usage.
+func (h *account) Show(c echo.Context) error {
+	c.Set("handler_method", "account.Show")
+
+	if err := setAccountHeaders(c, h.db, project(c)); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Update sets the account's metadata.
+// An empty value or a X-Remove-Account-Meta-* header removes the metadata.
+func (h *account) Update(c echo.Context) error {
+	c.Set("handler_method", "account.Update")
+
+	project := project(c)
+
+	// The project's ID is used as container ID for the account's metadata.
+	metas := metadata(c.Request().Header, "X-Account-Meta-")
+	if err := h.db.UpsertMetas(project.ID, "", metas); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// setAccountHeaders sets the account's metadata and usage headers.
+func setAccountHeaders(c echo.Context, db database.Client, project *model.Project) error {
+	metas, err := db.FindMeta(project.ID, "")
+	if err != nil && !db.IsNotFound(err) {
+		return err
+	}
+	setHeadersFromMeta(c, metas)
+
+	//
+
+	containers, err := db.ListContainers(project.ID)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	var bytes int64
+	for _, container := range containers {
+		count += container.Count
+		bytes += container.Bytes
+	}
+
+	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(project.CreatedAt.Unix(), 10))
+	c.Response().Header().Set("X-Account-Container-Count", strconv.Itoa(len(containers)))
+	c.Response().Header().Set("X-Account-Object-Count", strconv.Itoa(count))
+	c.Response().Header().Set("X-Account-Bytes-Used", strconv.FormatInt(bytes, 10))
+	return nil
+}
@@ -0,0 +1,300 @@
+package webserver
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/logger"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+	"github.com/mdouchement/openstackswift/internal/xpath"
+	"github.com/ncw/swift/v2"
+)
+
+const (
+	// maxDeletesPerRequest is the maximum number of paths of a bulk delete.
+	maxDeletesPerRequest = 10000
+	// maxFailedDeletes is the number of failures stopping a bulk delete.
+	maxFailedDeletes = 1000
+	// maxContainersPerExtraction is the maximum number of containers created by an archive extraction.
+	maxContainersPerExtraction = 10000
+	// maxFailedExtractions is the number of failures stopping an archive extraction.
+	maxFailedExtractions = 1000
+)
+
+type bulk struct {
+	logger  logger.Logger
+	db      database.Client
+	storage storage.Backend
+}
+
+// Delete removes the objects and the empty containers of the URL-encoded paths, one per line, of the request's body.
+//
+// https://docs.openstack.org/swift/latest/middleware.html#bulk-delete
+func (h *bulk) Delete(c echo.Context) error {
+	c.Set("handler_method", "bulk.Delete")
+
+	// A path is at most a container name, an object name and the separators, all URL-encoded.
+	payload, err := io.ReadAll(io.LimitReader(c.Request().Body, maxDeletesPerRequest*(3*(256+1024)+3)))
+	if err != nil {
+		return weberror.New(http.StatusBadRequest, err.Error())
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(payload), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+
+	deletion := new(service.Deletion)
+	if len(paths) > maxDeletesPerRequest {
+		body := fmt.Sprintf("Maximum Bulk Deletes: %d per request", maxDeletesPerRequest)
+		return renderBulk(c, "delete", nil, http.StatusRequestEntityTooLarge, body, deletion.Errors)
+	}
+
+	for _, p := range paths {
+		status, err := h.delete(project(c).ID, p)
+		if err != nil {
+			h.logger.Errorf("bulk.Delete: %s: %s", p, err)
+		}
+
+		switch status {
+		case http.StatusNoContent:
+			deletion.Deleted++
+		case http.StatusNotFound:
+			deletion.NotFound++
+		default:
+			deletion.Errors = append(deletion.Errors, [2]string{p, fmt.Sprintf("%d %s", status, http.StatusText(status))})
+		}
+
+		if len(deletion.Errors) >= maxFailedDeletes {
+			break
+		}
+	}
+
+	return renderDeletion(c, deletion)
+}
+
+// delete removes the object or the empty container of the given path and returns the status of the removal.
+func (h *bulk) delete(pid, p string) (int, error) {
+	containername, key := xpath.Entities(p)
+	if containername == "" {
+		return http.StatusBadRequest, nil
+	}
+
+	container, err := h.db.FindContainerByName(pid, containername)
+	if err != nil {
+		if h.db.IsNotFound(err) {
+			return http.StatusNotFound, nil
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	if key == "" {
+		objects, err := h.db.FindObjectsByContainerID(container.ID, 1, "")
+		if err != nil && !h.db.IsNotFound(err) {
+			return http.StatusInternalServerError, err
+		}
+		versions, err := h.db.FindVersions(container.ID, "")
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if len(objects) > 0 || len(versions) > 0 {
+			return http.StatusConflict, nil
+		}
+
+		if err = h.db.DeleteContainer(container.ID); err != nil {
+			return http.StatusInternalServerError, err
+		}
+		return http.StatusNoContent, nil
+	}
+
+	//
+
+	object, err := h.db.FindObjectByKey(container.ID, key)
+	if err != nil && !h.db.IsNotFound(err) {
+		return http.StatusInternalServerError, err
+	}
+	if h.db.IsNotFound(err) {
+		object = nil
+	}
+
+	var manifest *model.Manifest
+	if object == nil {
+		manifest, err = h.db.FindManifestByKey(container.ID, key)
+		if err != nil && !h.db.IsNotFound(err) {
+			return http.StatusInternalServerError, err
+		}
+		if h.db.IsNotFound(err) {
+			manifest = nil
+		}
+	}
+
+	if _, err = remove(h.db, h.storage, container, key, manifest, object); err != nil {
+		if status := weberror.StatusCode(err); status != http.StatusInternalServerError {
+			return status, nil
+		}
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusNoContent, nil
+}
+
+// Extract creates an object for each file of the tar archive of the request's body, compressed according to the extract-archive parameter.
+// The files are created under the request's path, the first directory of a file is its container when the archive is extracted in the account.
+//
+// https://docs.openstack.org/swift/latest/middleware.html#extract-archive
+func (h *bulk) Extract(c echo.Context) error {
+	c.Set("handler_method", "bulk.Extract")
+
+	var r io.Reader = c.Request().Body
+	switch c.QueryParam("extract-archive") {
+	case "tar":
+	case "tar.gz":
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return h.renderExtraction(c, 0, http.StatusBadRequest, "Invalid Tar File: "+err.Error(), nil)
+		}
+		defer gz.Close()
+		r = gz
+	case "tar.bz2":
+		r = bzip2.NewReader(r)
+	default:
+		return weberror.New(http.StatusBadRequest, "Unsupported archive format")
+	}
+
+	prefix := path.Join(c.Param("container"), c.Param("object"))
+	containers := map[string]*model.Container{}
+
+	var created int
+	var errs [][2]string
+
+	tr := tar.NewReader(r)
+	for len(errs) < maxFailedExtractions {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return h.renderExtraction(c, created, http.StatusBadRequest, "Invalid Tar File: "+err.Error(), errs)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue // Only the regular files are extracted.
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		containername, key, _ := strings.Cut(path.Join(prefix, name), "/")
+		if containername == "" || key == "" {
+			errs = append(errs, [2]string{"/" + path.Join(prefix, name), fmt.Sprintf("%d %s", http.StatusBadRequest, http.StatusText(http.StatusBadRequest))})
+			continue
+		}
+
+		container, ok := containers[containername]
+		if !ok {
+			if container, err = h.container(c, containername, len(containers)); err != nil {
+				return err
+			}
+			containers[containername] = container
+		}
+
+		status := http.StatusNotFound
+		if container != nil {
+			if err = h.upload(c, container, key, tr, header.Size); err != nil {
+				h.logger.Errorf("bulk.Extract: %s/%s: %s", containername, key, err)
+				status = weberror.StatusCode(err)
+				if e, ok := err.(*swift.Error); ok {
+					status = e.StatusCode
+				}
+			} else {
+				status = http.StatusCreated
+			}
+		}
+
+		if status != http.StatusCreated {
+			errs = append(errs, [2]string{"/" + containername + "/" + key, fmt.Sprintf("%d %s", status, http.StatusText(status))})
+			continue
+		}
+		created++
+	}
+
+	switch {
+	case len(errs) > 0:
+		return h.renderExtraction(c, created, http.StatusBadRequest, "", errs)
+	case created == 0:
+		return h.renderExtraction(c, created, http.StatusBadRequest, "Invalid Tar File: No Valid Files", errs)
+	}
+	return h.renderExtraction(c, created, http.StatusCreated, "", errs)
+}
+
+// container returns the container of the extracted files, it is created when the archive is extracted in the account.
+// A nil container is returned when it does not exist and can not be created.
+func (h *bulk) container(c echo.Context, name string, count int) (*model.Container, error) {
+	container, err := h.db.FindContainerByName(project(c).ID, name)
+	if err == nil {
+		return container, nil
+	}
+	if !h.db.IsNotFound(err) {
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if c.Param("container") != "" || count >= maxContainersPerExtraction {
+		return nil, nil
+	}
+
+	container = &model.Container{ProjectID: project(c).ID, Name: name}
+	if err = h.db.Save(container); err != nil {
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return container, nil
+}
+
+// upload creates or overwrites the object of the given key with the size bytes read from r.
+func (h *bulk) upload(c echo.Context, container *model.Container, key string, r io.Reader, size int64) error {
+	object, err := h.db.FindObjectByKey(container.ID, key)
+	if err != nil && !h.db.IsNotFound(err) {
+		return err
+	}
+	if h.db.IsNotFound(err) {
+		object = nil
+	}
+
+	previous, object := object, overwriting(object)
+	object.ContainerID = container.ID
+	object.Key = key
+	object.SymlinkTarget, object.SymlinkAccount, object.SymlinkEtag = "", "", ""
+	object.ContentType = mime.TypeByExtension(path.Ext(key))
+	if object.ContentType == "" {
+		object.ContentType = echo.MIMEOctetStream
+	}
+	if err = service.SetupObjectTTL(object, c.Request()); err != nil {
+		return err
+	}
+
+	uploader := service.NewObjectUploader(h.storage, container, object)
+	uploader.Expect("", size)
+	archiveOnCommit(h.db, h.storage, container, uploader, previous, object)
+	if err = uploader.Upload(r); err != nil {
+		return err
+	}
+
+	// The archive has no metadata, the ones of the overwritten object are removed.
+	return saveUpload(h.db, h.storage, container, object, nil)
+}
+
+// renderExtraction renders the report of an archive extraction.
+func (h *bulk) renderExtraction(c echo.Context, created, status int, body string, errs [][2]string) error {
+	return renderBulk(c, "extract", []bulkCounter{
+		{name: "Number Files Created", value: created},
+	}, status, body, errs)
+}
@@ -0,0 +1,89 @@
+package webserver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxRanges is the maximum number of ranges of a request, the Range header is ignored above.
+const maxRanges = 50
+
+// errUnsatisfiableRange is returned when none of the requested ranges overlaps the content.
+var errUnsatisfiableRange = errors.New("unsatisfiable range")
+
+// A byteRange is a range of bytes of a content.
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+// contentRange returns the Content-Range header's value of the range.
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// parseRange returns the satisfiable ranges of a `Range: bytes=' header for a content of the given size.
+// No ranges are returned when the header is absent, malformed or the content is empty, meaning the whole content is served.
+//
+// https://www.rfc-editor.org/rfc/rfc9110#name-range
+func parseRange(header string, size int64) ([]byteRange, error) {
+	specs, found := strings.CutPrefix(header, "bytes=")
+	if !found || size == 0 {
+		return nil, nil
+	}
+
+	values := strings.Split(specs, ",")
+	if len(values) > maxRanges {
+		return nil, nil
+	}
+
+	var ranges []byteRange
+	for _, value := range values {
+		first, last, found := strings.Cut(strings.TrimSpace(value), "-")
+		if !found {
+			return nil, nil
+		}
+
+		var r byteRange
+		switch {
+		case first == "":
+			// Suffix range: the last bytes of the content.
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, nil
+			}
+			if n == 0 {
+				continue
+			}
+			n = min(n, size)
+			r = byteRange{start: size - n, length: n}
+		default:
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, nil
+			}
+
+			end := size - 1
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, nil
+				}
+			}
+
+			if start >= size {
+				continue
+			}
+			end = min(end, size-1)
+			r = byteRange{start: start, length: end - start + 1}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, errUnsatisfiableRange
+	}
+	return ranges, nil
+}
@@ -0,0 +1,95 @@
+package webserver
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+)
+
+// preconditions evaluates the conditional headers of a GET or HEAD request against the entity's ETag and modification date.
+// It returns true when the request must not be processed, along with the rendering of the 304 or 412 status.
+//
+// https://www.rfc-editor.org/rfc/rfc7232#section-6
+func preconditions(c echo.Context, etag string, modified time.Time) (bool, error) {
+	r := c.Request()
+	modified = modified.Truncate(time.Second)
+
+	if header := r.Header.Get("If-Match"); header != "" {
+		if !etagMatch(header, etag, false) {
+			return true, weberror.New(http.StatusPreconditionFailed, "Precondition Failed")
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil && modified.After(since) {
+		return true, weberror.New(http.StatusPreconditionFailed, "Precondition Failed")
+	}
+
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		if etagMatch(header, etag, true) {
+			return true, notModified(c)
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+		return true, notModified(c)
+	}
+
+	return false, nil
+}
+
+// rangeApplies evaluates the If-Range header, the Range header must be ignored when the entity has changed.
+func rangeApplies(c echo.Context, etag string, modified time.Time) bool {
+	header := c.Request().Header.Get("If-Range")
+	if header == "" {
+		return true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return modified.Truncate(time.Second).Equal(date)
+	}
+	return etagMatch(header, etag, false)
+}
+
+// createOnly evaluates the `If-None-Match: *' header of a write request against the existence of the destination.
+func createOnly(c echo.Context, exists bool) error {
+	header := c.Request().Header.Get("If-None-Match")
+	if header == "" {
+		return nil
+	}
+	if header != "*" {
+		return weberror.New(http.StatusBadRequest, "If-None-Match only supports *")
+	}
+	if exists {
+		return weberror.New(http.StatusPreconditionFailed, "Precondition Failed")
+	}
+	return nil
+}
+
+// etagMatch returns true if one of the entity tags of the header matches the given ETag.
+// The weak comparison ignores the W/ prefix of weak entity tags.
+func etagMatch(header, etag string, weak bool) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" {
+			return true
+		}
+
+		if strings.HasPrefix(value, "W/") {
+			if !weak {
+				continue
+			}
+			value = strings.TrimPrefix(value, "W/")
+		}
+
+		if strings.Trim(value, `"`) == strings.Trim(etag, `"`) {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified renders the 304 status, the response keeps the entity's headers.
+func notModified(c echo.Context) error {
+	c.Response().Header().Del(echo.HeaderContentLength)
+	c.Response().Header().Del(echo.HeaderContentType)
+	return c.NoContent(http.StatusNotModified)
+}
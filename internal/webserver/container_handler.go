@@ -1,8 +1,10 @@
 package webserver
 
 import (
+	"mime"
 	"strings"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 	"fmt"
@@ -12,6 +14,7 @@ import (
 	"github.com/mdouchement/openstackswift/internal/database"
 	"github.com/mdouchement/openstackswift/internal/model"
 	"github.com/mdouchement/openstackswift/internal/webserver/serializer"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
 	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
 	"github.com/ncw/swift/v2"
 )
@@ -24,18 +27,38 @@ type container struct {
 func (h *container) List(c echo.Context) error {
 	c.Set("handler_method", "container.List")
 
-	containers, err := h.db.ListContainers()
+	opts, err := listOptions(c)
 	if err != nil {
+		return err
+	}
+
+	containers, err := h.db.ListContainerEntries(project(c).ID, opts)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if err = setAccountHeaders(c, h.db, project(c)); err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 
 	//
 
-	if c.Request().Header.Get("Accept") == "text/plain" {
-		return c.String(http.StatusOK, serializer.TextContainers(containers))
+	format, err := listingFormat(c)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case echo.MIMEApplicationJSON:
+		return c.JSON(http.StatusOK, serializer.Containers(containers))
+	case echo.MIMEApplicationXML, echo.MIMETextXML:
+		return h.xml(c, format, serializer.XMLContainers(project(c).Account(), containers))
+	}
+
+	if len(containers) == 0 {
+		return c.NoContent(http.StatusNoContent)
 	}
-	// "application/json"
-	return c.JSON(http.StatusOK, serializer.Containers(containers))
+	return c.String(http.StatusOK, serializer.TextContainers(containers))
 }
 
 func GetPathInt(c echo.Context, name string) (int, error) {
@@ -46,6 +69,95 @@ func GetPathInt(c echo.Context, name string) (int, error) {
 	return strconv.Atoi(val)
 }
 
+// listingLimit is the default and maximum number of entries of a listing.
+const listingLimit = 10000
+
+// listOptions returns the listing parameters of the request.
+func listOptions(c echo.Context) (database.ListOptions, error) {
+	opts := database.ListOptions{
+		Limit:     listingLimit,
+		Marker:    c.QueryParam("marker"),
+		EndMarker: c.QueryParam("end_marker"),
+		Prefix:    c.QueryParam("prefix"),
+		Delimiter: c.QueryParam("delimiter"),
+		Path:      c.QueryParam("path"),
+
+		VersionMarker: c.QueryParam("version_marker"),
+	}
+
+	if limit, err := GetPathInt(c, "limit"); err == nil && limit > 0 {
+		if limit > listingLimit {
+			return opts, weberror.New(http.StatusPreconditionFailed, fmt.Sprintf("Maximum limit is %d", listingLimit))
+		}
+		opts.Limit = limit
+	}
+
+	switch strings.ToLower(c.QueryParam("reverse")) {
+	case "true", "1", "yes", "on", "t", "y":
+		opts.Reverse = true
+	}
+	return opts, nil
+}
+
+// listingFormats are the media types of the listings, by order of preference.
+var listingFormats = []string{echo.MIMETextPlain, echo.MIMEApplicationJSON, echo.MIMEApplicationXML, echo.MIMETextXML}
+
+// listingFormat returns the media type of the listing requested by the format parameter or negotiated with the Accept header.
+func listingFormat(c echo.Context) (string, error) {
+	switch c.QueryParam("format") {
+	case "json":
+		return echo.MIMEApplicationJSON, nil
+	case "xml":
+		return echo.MIMEApplicationXML, nil
+	case "plain":
+		return echo.MIMETextPlain, nil
+	}
+
+	accept := c.Request().Header.Get("Accept")
+	if accept == "" {
+		return echo.MIMETextPlain, nil
+	}
+
+	var format string
+	var best float64
+	for _, offer := range listingFormats {
+		// The quality of an offer is the one of the most specific matching media range.
+		quality, specificity := 0.0, -1
+		for _, value := range strings.Split(accept, ",") {
+			mediatype, params, err := mime.ParseMediaType(value)
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+
+			offertype, _, _ := strings.Cut(offer, "/")
+			switch {
+			case mediatype == offer && specificity < 2:
+				quality, specificity = q, 2
+			case mediatype == offertype+"/*" && specificity < 1:
+				quality, specificity = q, 1
+			case mediatype == "*/*" && specificity < 0:
+				quality, specificity = q, 0
+			}
+		}
+
+		if quality > best {
+			format, best = offer, quality
+		}
+	}
+
+	if format == "" {
+		return "", weberror.New(http.StatusNotAcceptable, "Not Acceptable")
+	}
+	return format, nil
+}
+
 func setHeadersFromMeta(c echo.Context, metas []*model.Meta) error {
 	for _, meta := range metas {
 		c.Response().Header().Set(meta.Key, meta.Value)
@@ -56,7 +168,7 @@ func setHeadersFromMeta(c echo.Context, metas []*model.Meta) error {
 func (h *container) Show(c echo.Context) error {
 	c.Set("handler_method", "container.Show")
 
-	container, err := h.db.FindContainerByName(c.Param("container"))
+	container, err := h.db.FindContainerByName(project(c).ID, c.Param("container"))
 	if err != nil {
 		if h.db.IsNotFound(err) {
 			return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
@@ -65,27 +177,24 @@ func (h *container) Show(c echo.Context) error {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 
-	limit, err := GetPathInt(c, "limit")
-	if (err != nil) {
-		limit = -1
+	opts, err := listOptions(c)
+	if err != nil {
+		return err
 	}
 
-	h.logger.Debugf("container Show: container %v limit=%v prefix=%v", c.Param("container"), limit, c.QueryParam("prefix"))
+	h.logger.Debugf("container Show: container %v %+v", c.Param("container"), opts)
 
-	objects, err := h.db.FindObjectsByContainerID(container.ID, limit, c.QueryParam("prefix"))
+	objects, err := h.db.ListObjectEntries(container.ID, opts)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
 
-	/*
-	// Delimiter spliter maybe can be removed
-	delimiter := c.QueryParam("delimiter")
-	if (delimiter != "") {
-		for i := 0; i < len(objects); i++ {
-			item := objects[i]
-			item.Key = item.Key[strings.LastIndex(item.Key, string([]rune(delimiter)[0]))+1:]
+	var versions []*database.VersionEntry
+	if _, ok := c.QueryParams()["versions"]; ok {
+		versions, err = h.db.ListVersionEntries(container.ID, opts)
+		if err != nil {
+			return weberror.New(http.StatusInternalServerError, err.Error())
 		}
-	}*/
-
-	if err != nil && !h.db.IsNotFound(err) {
-		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 
 	// get metas and set headers
@@ -102,44 +211,89 @@ func (h *container) Show(c echo.Context) error {
 
 	//
 
-	container.Count = len(objects)
 	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
 	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(container.CreatedAt.Unix(), 10))
 	c.Response().Header().Set("X-Container-Object-Count", strconv.Itoa(container.Count))
-	c.Response().Header().Set("X-Container-Bytes-Used", "0")
+	c.Response().Header().Set("X-Container-Bytes-Used", strconv.FormatInt(container.Bytes, 10))
 
 	switch c.Request().Method {
 	case http.MethodHead:
 		return c.NoContent(http.StatusOK)
 	case http.MethodGet:
-		prefix := c.Request().Header.Get("prefix")
+		format, err := listingFormat(c)
+		if err != nil {
+			return err
+		}
+
+		if versions != nil {
+			return h.versions(c, format, container, versions)
+		}
+
+		switch format {
+		case echo.MIMEApplicationJSON:
+			return c.JSON(http.StatusOK, serializer.Objects(objects))
+		case echo.MIMEApplicationXML, echo.MIMETextXML:
+			return h.xml(c, format, serializer.XMLObjects(container.Name, objects))
+		}
 
-		if c.Request().Header.Get("Accept") == "text/plain" {
-			return c.String(http.StatusOK, serializer.TextObjects(objects, prefix))
+		if len(objects) == 0 {
+			return c.NoContent(http.StatusNoContent)
 		}
-		// "application/json"
-		return c.JSON(http.StatusOK, serializer.Objects(objects, prefix))
+		return c.String(http.StatusOK, serializer.TextObjects(objects))
 	}
 	return weberror.New(http.StatusNotFound, swift.BadRequest.Text)
 }
 
+// versions renders the versions listing of the container with the given media type.
+func (h *container) versions(c echo.Context, format string, container *model.Container, versions []*database.VersionEntry) error {
+	switch format {
+	case echo.MIMEApplicationJSON:
+		return c.JSON(http.StatusOK, serializer.Versions(versions))
+	case echo.MIMEApplicationXML, echo.MIMETextXML:
+		return h.xml(c, format, serializer.XMLVersions(container.Name, versions))
+	}
+
+	if len(versions) == 0 {
+		return c.NoContent(http.StatusNoContent)
+	}
+	return c.String(http.StatusOK, serializer.TextVersions(versions))
+}
+
+// xml renders the given listing with the negotiated XML media type, application/xml by default.
+func (h *container) xml(c echo.Context, format string, listing interface{}) error {
+	if format == echo.MIMETextXML {
+		c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextXMLCharsetUTF8)
+	}
+	return c.XML(http.StatusOK, listing)
+}
+
 func (h *container) Create(c echo.Context) error {
 	c.Set("handler_method", "container.Create")
 
-	container, err := h.db.FindContainerByName(c.Param("container"))
+	container, err := h.db.FindContainerByName(project(c).ID, c.Param("container"))
 	if err != nil && !h.db.IsNotFound(err) {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 
 	created := h.db.IsNotFound(err)
 	if created {
-		container = &model.Container{Name: c.Param("container"), Count: 0, Bytes: 0}
+		container = &model.Container{ProjectID: project(c).ID, Name: c.Param("container"), Count: 0, Bytes: 0}
 		err = h.db.Save(container)
 		if err != nil {
 			return weberror.New(http.StatusInternalServerError, err.Error())
 		}
 	}
 
+	if err = h.updateVersionsEnabled(c, container); err != nil {
+		return err
+	}
+	if err = h.updateVersioning(c, container); err != nil {
+		return err
+	}
+	if err = h.updateMetadata(c, container); err != nil {
+		return err
+	}
+
 	//
 
 	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
@@ -156,7 +310,7 @@ func (h *container) Create(c echo.Context) error {
 func (h *container) Update(c echo.Context) error {
 	c.Set("handler_method", "container.Update")
 
-	container, err := h.db.FindContainerByName(c.Param("container"))
+	container, err := h.db.FindContainerByName(project(c).ID, c.Param("container"))
 	if err != nil && !h.db.IsNotFound(err) {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
@@ -165,33 +319,136 @@ func (h *container) Update(c echo.Context) error {
 		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
 	}
 
-	// Create and update metadata
-	for key, values := range c.Request().Header {
-		if len(values) == 0 {
-			continue
+	if err = h.updateVersionsEnabled(c, container); err != nil {
+		return err
+	}
+	if err = h.updateVersioning(c, container); err != nil {
+		return err
+	}
+	if err = h.updateMetadata(c, container); err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(container.CreatedAt.Unix(), 10))
+	return c.NoContent(http.StatusAccepted)
+}
+
+// updateMetadata persists the user metadata along with the read/write access-control
+// headers so that container ACLs survive a round-trip.
+func (h *container) updateMetadata(c echo.Context, container *model.Container) error {
+	metas := metadata(c.Request().Header, "X-Container-Meta-", "X-Container-Read", "X-Container-Write")
+	if err := h.db.UpsertMetas(container.ID, "", metas); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+// updateVersioning enables, changes or disables the legacy versioning of the container according to the
+// X-Versions-Location, X-History-Location and their X-Remove-* headers. Only one versioning mode can be enabled.
+func (h *container) updateVersioning(c echo.Context, container *model.Container) error {
+	header := c.Request().Header
+
+	var mode, location string
+	for _, key := range []string{service.VersionsLocation, service.HistoryLocation} {
+		if values, ok := header[key]; ok {
+			if mode != "" {
+				return weberror.New(http.StatusBadRequest, "Only one of X-Versions-Location or X-History-Location may be specified")
+			}
+			mode, location = key, values[0]
 		}
-		// Persist user metadata along with the read/write access-control
-		// headers so that container ACLs survive a round-trip.
-		if !strings.HasPrefix(key, "X-Container-Meta-") &&
-			key != "X-Container-Read" && key != "X-Container-Write" {
-			continue
+		if header.Get("X-Remove-"+strings.TrimPrefix(key, "X-")) != "" && mode == "" {
+			mode = key
 		}
-		// no string to mark only container
-		_, err := h.db.AddMeta(container.ID, "", key, values[0])
+	}
+	if mode == "" {
+		return nil
+	}
+
+	if location != "" {
+		enabled, err := h.versionsEnabled(container)
 		if err != nil {
+			return err
+		}
+		if enabled {
+			return weberror.New(http.StatusBadRequest, "Cannot set "+mode+" on a container with "+service.VersionsEnabled)
+		}
+	}
+
+	if unescaped, err := url.PathUnescape(location); err == nil {
+		location = unescaped
+	}
+	if strings.Contains(location, "/") || location == container.Name {
+		return weberror.New(http.StatusBadRequest, "Invalid versions location")
+	}
+
+	//
+
+	for _, key := range []string{service.VersionsLocation, service.HistoryLocation} {
+		if err := h.db.DeleteMeta(container.ID, "", key); err != nil && !h.db.IsNotFound(err) {
 			return weberror.New(http.StatusInternalServerError, err.Error())
 		}
 	}
+	if location == "" {
+		return nil
+	}
+	if _, err := h.db.AddMeta(container.ID, "", mode, location); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
 
-	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
-	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(container.CreatedAt.Unix(), 10))
-	return c.NoContent(http.StatusAccepted)
+// updateVersionsEnabled enables or disables the object versioning of the container according to the X-Versions-Enabled header.
+// The object versioning can not be enabled along with the legacy versioning.
+func (h *container) updateVersionsEnabled(c echo.Context, container *model.Container) error {
+	value := c.Request().Header.Get(service.VersionsEnabled)
+	if value == "" {
+		return nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return weberror.New(http.StatusBadRequest, "Invalid "+service.VersionsEnabled)
+	}
+
+	metas, err := h.db.FindMeta(container.ID, "")
+	if err != nil && !h.db.IsNotFound(err) {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	for _, meta := range metas {
+		if enabled && (meta.Key == service.VersionsLocation || meta.Key == service.HistoryLocation) {
+			return weberror.New(http.StatusBadRequest, "Cannot enable object versioning on a container with "+meta.Key)
+		}
+	}
+
+	//
+
+	if err = h.db.DeleteMeta(container.ID, "", service.VersionsEnabled); err != nil && !h.db.IsNotFound(err) {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	value = "False"
+	if enabled {
+		value = "True"
+	}
+	if _, err = h.db.AddMeta(container.ID, "", service.VersionsEnabled, value); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return nil
+}
+
+// versionsEnabled returns true when the object versioning of the container is enabled.
+func (h *container) versionsEnabled(container *model.Container) (bool, error) {
+	history, err := service.NewVersionHistory(h.db, nil, container)
+	if err != nil {
+		return false, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return history.Enabled(), nil
 }
 
 func (h *container) Delete(c echo.Context) error {
 	c.Set("handler_method", "container.Delete")
 
-	container, err := h.db.FindContainerByName(c.Param("container"))
+	container, err := h.db.FindContainerByName(project(c).ID, c.Param("container"))
 	if err != nil {
 		if h.db.IsNotFound(err) {
 			return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
@@ -211,6 +468,16 @@ func (h *container) Delete(c echo.Context) error {
 		return weberror.New(http.StatusConflict, swift.ContainerNotEmpty.Text)
 	}
 
+	// The previous versions of the objects must be deleted first.
+	versions, err := h.db.FindVersions(container.ID, "")
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if len(versions) > 0 {
+		return weberror.New(http.StatusConflict, swift.ContainerNotEmpty.Text)
+	}
+
 	//
 
 	err = h.db.DeleteContainer(container.ID)
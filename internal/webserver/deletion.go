@@ -0,0 +1,111 @@
+package webserver
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
+)
+
+// A bulkCounter is a named number of a bulk report.
+type bulkCounter struct {
+	name  string
+	value int
+}
+
+// renderDeletion renders the report of a removal of several objects, in JSON, XML or plain text according to the request.
+// The response's status is always 200, the outcome is given by the report's Response Status.
+func renderDeletion(c echo.Context, deletion *service.Deletion) error {
+	status := http.StatusOK
+	if len(deletion.Errors) > 0 {
+		status = http.StatusBadRequest
+	}
+
+	return renderBulk(c, "delete", []bulkCounter{
+		{name: "Number Deleted", value: deletion.Deleted},
+		{name: "Number Not Found", value: deletion.NotFound},
+	}, status, "", deletion.Errors)
+}
+
+// renderBulk renders the report of a bulk operation with the given counters, Response Status, Response Body and errors.
+// The XML report uses the given root element.
+func renderBulk(c echo.Context, root string, counters []bulkCounter, status int, body string, errors [][2]string) error {
+	statusline := fmt.Sprintf("%d %s", status, http.StatusText(status))
+
+	errs := make([][]string, 0, len(errors))
+	for _, e := range errors {
+		errs = append(errs, []string{e[0], e[1]})
+	}
+
+	format, err := listingFormat(c)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case echo.MIMEApplicationJSON:
+		report := map[string]interface{}{
+			"Response Body":   body,
+			"Response Status": statusline,
+			"Errors":          errs,
+		}
+		for _, counter := range counters {
+			report[counter.name] = counter.value
+		}
+
+		payload, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		// Clients detect the support of the bulk operations by the exact media type.
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, payload)
+	case echo.MIMEApplicationXML, echo.MIMETextXML:
+		element := func(name string) string {
+			return strings.ReplaceAll(strings.ToLower(name), " ", "_")
+		}
+
+		var b strings.Builder
+		b.WriteString(xml.Header)
+		fmt.Fprintf(&b, "<%s>\n", root)
+		for _, counter := range counters {
+			fmt.Fprintf(&b, "<%s>%d</%[1]s>\n", element(counter.name), counter.value)
+		}
+		fmt.Fprintf(&b, "<response_body>%s</response_body>\n", xmlEscape(body))
+		fmt.Fprintf(&b, "<response_status>%s</response_status>\n", statusline)
+		b.WriteString("<errors>\n")
+		for _, e := range errs {
+			fmt.Fprintf(&b, "<object><name>%s</name><status>%s</status></object>\n", xmlEscape(e[0]), xmlEscape(e[1]))
+		}
+		b.WriteString("</errors>\n")
+		fmt.Fprintf(&b, "</%s>\n", root)
+
+		contenttype := echo.MIMEApplicationXMLCharsetUTF8
+		if format == echo.MIMETextXML {
+			contenttype = echo.MIMETextXMLCharsetUTF8
+		}
+		return c.Blob(http.StatusOK, contenttype, []byte(b.String()))
+	}
+
+	var b strings.Builder
+	for _, counter := range counters {
+		fmt.Fprintf(&b, "%s: %d\n", counter.name, counter.value)
+	}
+	fmt.Fprintf(&b, "Response Body: %s\n", body)
+	fmt.Fprintf(&b, "Response Status: %s\n", statusline)
+	fmt.Fprintf(&b, "Errors:\n")
+	for _, e := range errs {
+		fmt.Fprintf(&b, "%s, %s\n", e[0], e[1])
+	}
+	return c.String(http.StatusOK, b.String())
+}
+
+// xmlEscape returns the given text escaped for an XML element.
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
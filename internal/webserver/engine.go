@@ -5,12 +5,15 @@ import (
 	"net/http"
 	"path"
 	"sort"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/mdouchement/logger"
 	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
 	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
 	middlewarepkg "github.com/mdouchement/openstackswift/internal/webserver/middleware"
 )
 
@@ -20,15 +23,21 @@ type Controller struct {
 	Logger   logger.Logger
 	Database database.Client
 	Storage  storage.Backend
-	//
-	Tenant   string
-	Domain   string
-	Username string
-	Password string
+	// TokenTTL is the lifetime of the issued auth tokens, one hour if not defined.
+	TokenTTL time.Duration
+	// Domain is the users' domain for the auth versions unaware of domains, Default if not defined.
+	Domain string
 }
 
 // EchoEngine instantiates the wep server.
 func EchoEngine(ctrl Controller) *echo.Echo {
+	if ctrl.TokenTTL == 0 {
+		ctrl.TokenTTL = time.Hour
+	}
+	if ctrl.Domain == "" {
+		ctrl.Domain = "Default"
+	}
+
 	engine := echo.New()
 	// engine.Use(middleware.Recover())
 	engine.Use(middleware.Gzip())
@@ -38,7 +47,7 @@ func EchoEngine(ctrl Controller) *echo.Echo {
 	engine.HTTPErrorHandler = middlewarepkg.NewHTTPErrorHandler(ctrl.Logger)
 
 	engine.Pre(middleware.Rewrite(map[string]string{
-		"/": "/version",
+		"^/": "/version",
 	}))
 
 	//
@@ -55,24 +64,114 @@ func EchoEngine(ctrl Controller) *echo.Echo {
 		})
 	})
 
-	// Keystone
+	// Capabilities of the cluster used by the clients to discover the supported features.
+	router.GET("/info", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, echo.Map{
+			"swift": echo.Map{
+				"version": ctrl.Version,
+			},
+			"slo": echo.Map{
+				"max_manifest_segments": service.MaxManifestSegments,
+				"max_manifest_size":     service.MaxManifestSize,
+				"min_segment_size":      1,
+			},
+			"symlink": echo.Map{
+				"symloop_max": symloopMax,
+			},
+			"bulk_delete": echo.Map{
+				"max_deletes_per_request": maxDeletesPerRequest,
+				"max_failed_deletes":      maxFailedDeletes,
+			},
+			"bulk_upload": echo.Map{
+				"max_containers_per_extraction": maxContainersPerExtraction,
+				"max_failed_extractions":        maxFailedExtractions,
+			},
+		})
+	})
+
+	// Authentication (TempAuth v1.0, Keystone v2.0 and v3)
 	//
+	id := identity{
+		db:            ctrl.Database,
+		ttl:           ctrl.TokenTTL,
+		defaultDomain: ctrl.Domain,
+	}
+
+	t1 := tempauth{
+		identity: id,
+		logger:   ctrl.Logger,
+	}
+	router.GET("/auth/v1.0", t1.Authenticate)
+	router.GET("/v1.0", t1.Authenticate)
+
+	k2 := keystone2{
+		identity: id,
+		logger:   ctrl.Logger,
+	}
+	router.POST("/v2.0/tokens", k2.Authenticate)
+
 	k3 := keystone3{
+		identity: id,
 		logger:   ctrl.Logger,
-		tenant:   ctrl.Tenant,
-		domain:   ctrl.Domain,
-		username: ctrl.Username,
-		password: ctrl.Password,
 	}
+	router.GET("/v3", k3.Discovery)
+	router.GET("/v3/", k3.Discovery)
 	router.POST("/v3/auth/tokens", k3.Authenticate)
+	router.GET("/v3/auth/tokens", k3.Validate)
+	router.HEAD("/v3/auth/tokens", k3.Validate)
+	router.DELETE("/v3/auth/tokens", k3.Revoke)
+	router.GET("/v3/auth/catalog", k3.Catalog)
+	router.POST("/v3/users/:user_id/application_credentials", k3.CreateCredential)
+	router.GET("/v3/users/:user_id/application_credentials", k3.ListCredentials)
+	router.DELETE("/v3/users/:user_id/application_credentials/:id", k3.DeleteCredential)
 
 	// Swift
 	//
 	// https://docs.openstack.org/api-ref/object-store/index.html
 	//
 
-	swift := router.Group("/v1/AUTH_" + ctrl.Username)
-	auth := middlewarepkg.Authenticate(CraftToken(ctrl.Username))
+	swift := router.Group("/v1/AUTH_:account")
+	auth := middlewarepkg.Authenticate(ctrl.Database)
+
+	formpost := formpost{
+		logger:  ctrl.Logger,
+		db:      ctrl.Database,
+		storage: ctrl.Storage,
+	}
+
+	// Account
+	//
+	account := account{
+		logger: ctrl.Logger,
+		db:     ctrl.Database,
+	}
+	swift.HEAD("", account.Show, auth)
+	swift.HEAD("/", account.Show, auth)
+	bulk := bulk{
+		logger:  ctrl.Logger,
+		db:      ctrl.Database,
+		storage: ctrl.Storage,
+	}
+	for _, p := range []string{"", "/"} {
+		swift.POST(p, func(c echo.Context) error {
+			if c.QueryParams().Has("bulk-delete") {
+				return bulk.Delete(c)
+			}
+			return account.Update(c)
+		}, auth)
+		swift.DELETE(p, func(c echo.Context) error {
+			if c.QueryParams().Has("bulk-delete") {
+				return bulk.Delete(c)
+			}
+			return echo.ErrMethodNotAllowed
+		}, auth)
+		swift.PUT(p, func(c echo.Context) error {
+			if c.QueryParams().Has("extract-archive") {
+				return bulk.Extract(c)
+			}
+			return echo.ErrMethodNotAllowed
+		}, auth)
+	}
 
 	// Container
 	//
@@ -84,8 +183,18 @@ func EchoEngine(ctrl Controller) *echo.Echo {
 	swift.GET("/", container.List, auth) // tolerate a trailing slash (/v1/AUTH_x/)
 	swift.HEAD("/:container", container.Show, auth) // check existence
 	swift.GET("/:container", container.Show, auth)
-	swift.PUT("/:container", container.Create, auth)
-	swift.POST("/:container", container.Update, auth)
+	swift.PUT("/:container", func(c echo.Context) error {
+		if c.QueryParams().Has("extract-archive") {
+			return bulk.Extract(c)
+		}
+		return container.Create(c)
+	}, auth)
+	swift.POST("/:container", func(c echo.Context) error {
+		if middlewarepkg.IsFormPost(c.Request()) {
+			return formpost.Upload(c)
+		}
+		return container.Update(c)
+	}, auth)
 	swift.DELETE("/:container", container.Delete, auth)
 
 	// Object
@@ -99,9 +208,17 @@ func EchoEngine(ctrl Controller) *echo.Echo {
 	swift.GET("/:container/:object", object.Download, auth)
 	swift.PUT("/:container/:object", func(c echo.Context) error {
 		switch {
+		case c.QueryParams().Has("extract-archive"):
+			return bulk.Extract(c)
+		case c.QueryParam("multipart-manifest") == "put":
+			return object.StaticManifest(c)
+		case c.Request().Header.Get("X-Symlink-Target") != "":
+			return object.Symlink(c)
 		case c.Request().Header.Get("X-Copy-From") != "":
 			c.Set("object_source", c.Request().Header.Get("X-Copy-From"))
+			c.Set("object_source_account", c.Request().Header.Get("X-Copy-From-Account"))
 			c.Set("object_destination", path.Join(c.Param("container"), c.Param("object")))
+			c.Set("object_destination_account", "")
 			return object.Copy(c)
 		case c.Request().Header.Get("X-Object-Manifest") != "":
 			return object.Manifest(c)
@@ -111,16 +228,28 @@ func EchoEngine(ctrl Controller) *echo.Echo {
 	}, auth)
 	swift.Add("COPY", "/:container/:object", func(c echo.Context) error {
 		c.Set("object_source", path.Join(c.Param("container"), c.Param("object")))
+		c.Set("object_source_account", "")
 		c.Set("object_destination", c.Request().Header.Get("Destination"))
+		c.Set("object_destination_account", c.Request().Header.Get("Destination-Account"))
 		return object.Copy(c)
 	}, auth)
 
-	swift.POST("/:container/:object", object.Update, auth)
+	swift.POST("/:container/:object", func(c echo.Context) error {
+		if middlewarepkg.IsFormPost(c.Request()) {
+			return formpost.Upload(c) // The object is the prefix of the uploaded files.
+		}
+		return object.Update(c)
+	}, auth)
 	swift.DELETE("/:container/:object", object.Delete, auth)
 
 	return engine
 }
 
+// baseURL returns the scheme and host used to reach the server.
+func baseURL(c echo.Context) string {
+	return c.Scheme() + "://" + c.Request().Host
+}
+
 // PrintRoutes prints the Echo engin exposed routes.
 func PrintRoutes(e *echo.Echo) {
 	ignored := map[string]bool{
@@ -143,7 +272,7 @@ func PrintRoutes(e *echo.Echo) {
 	}
 }
 
-// CraftToken returns the auth token for a user.
-func CraftToken(username string) string {
-	return "tk_" + username
+// project returns the authenticated project (aka Swift account) of the request.
+func project(c echo.Context) *model.Project {
+	return c.Get("project").(*model.Project)
 }
@@ -0,0 +1,168 @@
+package webserver
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/logger"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	middlewarepkg "github.com/mdouchement/openstackswift/internal/webserver/middleware"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
+	"github.com/ncw/swift/v2"
+)
+
+type formpost struct {
+	logger  logger.Logger
+	db      database.Client
+	storage storage.Backend
+}
+
+// Upload stores each file of the form as an object named by the path's prefix and the filename.
+// The signature of the form is checked by the Authenticate middleware, unless the request is made by the account owner.
+//
+// https://docs.openstack.org/swift/latest/api/form_post_middleware.html
+func (h *formpost) Upload(c echo.Context) error {
+	c.Set("handler_method", "formpost.Upload")
+
+	if err := c.Request().ParseMultipartForm(middlewarepkg.FormPostMemory); err != nil {
+		var maxbytes *http.MaxBytesError
+		if errors.As(err, &maxbytes) {
+			return h.render(c, http.StatusBadRequest, "max_file_size exceeded")
+		}
+		return h.render(c, http.StatusBadRequest, err.Error())
+	}
+	form := c.Request().MultipartForm
+
+	var files []*multipart.FileHeader
+	for _, fields := range form.File {
+		for _, file := range fields {
+			if file.Filename != "" {
+				files = append(files, file)
+			}
+		}
+	}
+
+	maxsize, err := strconv.ParseInt(h.attribute(c, "max_file_size"), 10, 64)
+	if err != nil {
+		return h.render(c, http.StatusBadRequest, "max_file_size is invalid")
+	}
+	maxcount, err := strconv.Atoi(h.attribute(c, "max_file_count"))
+	if err != nil {
+		return h.render(c, http.StatusBadRequest, "max_file_count is invalid")
+	}
+
+	if len(files) > maxcount {
+		return h.render(c, http.StatusBadRequest, "max_file_count exceeded")
+	}
+	for _, file := range files {
+		if file.Size > maxsize {
+			return h.render(c, http.StatusBadRequest, "max_file_size exceeded")
+		}
+	}
+
+	//
+
+	container, err := h.db.FindContainerByName(project(c).ID, c.Param("container"))
+	if err != nil {
+		if h.db.IsNotFound(err) {
+			return h.render(c, http.StatusNotFound, swift.ContainerNotFound.Text)
+		}
+		return h.render(c, http.StatusInternalServerError, err.Error())
+	}
+
+	// The object expiration is given by form fields instead of headers.
+	for field, header := range map[string]string{"x_delete_at": "X-Delete-At", "x_delete_after": "X-Delete-After"} {
+		if value := h.attribute(c, field); value != "" {
+			c.Request().Header.Set(header, value)
+		}
+	}
+
+	for _, file := range files {
+		if err = h.upload(c, container, file); err != nil {
+			return h.render(c, http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return h.render(c, http.StatusCreated, "")
+}
+
+func (h *formpost) upload(c echo.Context, container *model.Container, file *multipart.FileHeader) error {
+	key := c.Param("object") + file.Filename
+
+	object, err := h.db.FindObjectByKey(container.ID, key)
+	if err != nil && !h.db.IsNotFound(err) {
+		return err
+	}
+	if h.db.IsNotFound(err) {
+		object = nil
+	}
+
+	previous, object := object, overwriting(object)
+	object.ContainerID = container.ID
+	object.Key = key
+	object.SymlinkTarget, object.SymlinkAccount, object.SymlinkEtag = "", "", ""
+	object.ContentType = file.Header.Get("Content-Type")
+	if object.ContentType == "" {
+		object.ContentType = echo.MIMEOctetStream
+	}
+	if err = service.SetupObjectTTL(object, c.Request()); err != nil {
+		return err
+	}
+
+	//
+
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	uploader := service.NewObjectUploader(h.storage, container, object)
+	archiveOnCommit(h.db, h.storage, container, uploader, previous, object)
+	if err = uploader.Upload(r); err != nil {
+		return err
+	}
+
+	// The form has no metadata, the ones of the overwritten object are removed.
+	return saveUpload(h.db, h.storage, container, object, nil)
+}
+
+// attribute returns the attribute checked with the form's signature, or the form's field for the account owner.
+func (h *formpost) attribute(c echo.Context, name string) string {
+	if attributes, ok := c.Get("formpost_attributes").(url.Values); ok {
+		return attributes.Get(name)
+	}
+	return c.FormValue(name)
+}
+
+// render responds with the given status, or redirects to the form's redirect with the status and the message as query parameters.
+func (h *formpost) render(c echo.Context, status int, message string) error {
+	redirect := h.attribute(c, "redirect")
+	if redirect == "" {
+		return c.HTML(status, fmt.Sprintf(
+			"<html><body><p><h1>Status: %d %s</h1><h1>Message: %s</h1></p></body></html>",
+			status, http.StatusText(status), html.EscapeString(message),
+		))
+	}
+
+	separator := "?"
+	if strings.Contains(redirect, "?") {
+		separator = "&"
+	}
+	redirect += separator + "status=" + strconv.Itoa(status) + "&message=" + url.QueryEscape(message)
+
+	c.Response().Header().Set(echo.HeaderLocation, redirect)
+	return c.HTML(http.StatusSeeOther, fmt.Sprintf(
+		`<html><body><p><a href="%s">Click to continue...</a></p></body></html>`,
+		html.EscapeString(redirect),
+	))
+}
@@ -0,0 +1,197 @@
+package webserver
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+	"github.com/ncw/swift/v2"
+)
+
+// An identity holds the credentials checks and the tokens issuance shared by all the auth versions.
+type identity struct {
+	db  database.Client
+	ttl time.Duration
+	// defaultDomain is the domain of the users authenticated with an auth version unaware of domains.
+	defaultDomain string
+}
+
+// authenticate returns the user of the default domain matching the given credentials.
+// The project is optional and identified by its ID or its name.
+func (h *identity) authenticate(username, password, projectID, projectName string) (*model.User, error) {
+	user, err := h.user(v3User{Name: username}, &v3Domain{Name: h.defaultDomain})
+	if user == nil || err != nil {
+		return nil, err
+	}
+
+	if !database.CheckPassword(user, password) {
+		return nil, nil
+	}
+
+	if projectID == "" && projectName == "" {
+		return user, nil
+	}
+	return h.scoped(user, &v3Scope{
+		Project: &v3Project{
+			ID:     projectID,
+			Name:   projectName,
+			Domain: &v3Domain{Name: h.defaultDomain},
+		},
+	})
+}
+
+// A grant bounds the tokens issued from a previous authentication, a token or an application credential.
+// The issued tokens do not outlive the grant, a zero expiration does not bound them, and keep its methods.
+type grant struct {
+	expiresAt time.Time
+	methods   []string
+}
+
+// retoken returns the owner of the given valid token and the grant of the token.
+func (h *identity) retoken(params *v3AuthToken) (*model.User, *grant, error) {
+	if params == nil {
+		return nil, nil, errors.New("missing Auth.Identity.Token")
+	}
+
+	token, err := h.db.FindTokenByKey(params.ID)
+	if err != nil {
+		return nil, nil, h.notFound(err)
+	}
+	if token.Expired() {
+		return nil, nil, nil
+	}
+
+	user, err := h.db.FindUser(token.UserID)
+	if err != nil {
+		return nil, nil, h.notFound(err)
+	}
+	return user, &grant{expiresAt: token.ExpiresAt, methods: token.Methods}, nil
+}
+
+// issue returns a new token for the user, bounded by the grant if any.
+func (h *identity) issue(user *model.User, g *grant, methods ...string) (*model.Token, error) {
+	expiresAt := time.Now().Add(h.ttl).UTC()
+	if g != nil {
+		if !g.expiresAt.IsZero() && g.expiresAt.Before(expiresAt) {
+			expiresAt = g.expiresAt
+		}
+		for _, method := range g.methods {
+			if !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+	}
+	return database.IssueToken(h.db, user, expiresAt, methods...)
+}
+
+// storageURL returns the Swift endpoint of the given project.
+func (h *identity) storageURL(c echo.Context, project *model.Project) string {
+	return baseURL(c) + "/v1/" + project.Account()
+}
+
+// token returns the valid token identified by the given key.
+func (h *identity) token(key string) (*model.Token, error) {
+	token, err := h.db.FindTokenByKey(key)
+	if err != nil {
+		if h.db.IsNotFound(err) {
+			return nil, weberror.New(http.StatusUnauthorized, swift.AuthorizationFailed.Text)
+		}
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if token.Expired() {
+		return nil, weberror.New(http.StatusUnauthorized, swift.AuthorizationFailed.Text)
+	}
+	return token, nil
+}
+
+// user returns the user identified by its ID or by its name and domain.
+func (h *identity) user(params v3User, domain *v3Domain) (*model.User, error) {
+	if params.ID != "" {
+		user, err := h.db.FindUser(params.ID)
+		if err != nil {
+			return nil, h.notFound(err)
+		}
+		return user, nil
+	}
+
+	if domain == nil {
+		return nil, errors.New("missing user domain")
+	}
+
+	d, err := h.domain(*domain)
+	if d == nil || err != nil {
+		return nil, err
+	}
+
+	user, err := h.db.FindUserByName(d.ID, params.Name)
+	if err != nil {
+		return nil, h.notFound(err)
+	}
+	return user, nil
+}
+
+// domain returns the domain identified by its ID or its name.
+func (h *identity) domain(params v3Domain) (*model.Domain, error) {
+	var domain *model.Domain
+	var err error
+	if params.ID != "" {
+		domain, err = h.db.FindDomain(params.ID)
+	} else {
+		domain, err = h.db.FindDomainByName(params.Name)
+	}
+	if err != nil {
+		return nil, h.notFound(err)
+	}
+	return domain, nil
+}
+
+// scoped returns the user if it is allowed to access the requested scope.
+// Without scope, the user's project is used.
+func (h *identity) scoped(user *model.User, scope *v3Scope) (*model.User, error) {
+	if scope == nil {
+		return user, nil
+	}
+	if scope.Project == nil {
+		return nil, errors.New("only project scope is supported")
+	}
+
+	if scope.Project.ID != "" {
+		if scope.Project.ID != user.ProjectID {
+			return nil, nil
+		}
+		return user, nil
+	}
+
+	if scope.Project.Domain == nil {
+		return nil, errors.New("missing Auth.Scope.Project.Domain")
+	}
+
+	domain, err := h.domain(*scope.Project.Domain)
+	if domain == nil || err != nil {
+		return nil, err
+	}
+
+	project, err := h.db.FindProjectByName(domain.ID, scope.Project.Name)
+	if err != nil {
+		return nil, h.notFound(err)
+	}
+
+	if project.ID != user.ProjectID {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// notFound swallows not found errors so they are reported as invalid credentials.
+func (h *identity) notFound(err error) error {
+	if h.db.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+package webserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/logger"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+	"github.com/ncw/swift/v2"
+)
+
+// keystone2 handles the Keystone v2.0 authentication.
+type keystone2 struct {
+	identity
+	logger logger.Logger
+}
+
+func (h *keystone2) Authenticate(c echo.Context) error {
+	c.Set("handler_method", "keystone2.Authenticate")
+
+	// Filter params
+	var params keystone2params
+	if err := c.Bind(&params); err != nil {
+		return weberror.New(http.StatusBadRequest, swift.BadRequest.Text)
+	}
+
+	// Authorization
+	var user *model.User
+	var parent *grant
+	var err error
+	method := "password"
+
+	switch {
+	case params.Auth.PasswordCredentials != nil:
+		user, err = h.authenticate(
+			params.Auth.PasswordCredentials.Username,
+			params.Auth.PasswordCredentials.Password,
+			params.Auth.TenantID,
+			params.Auth.TenantName,
+		)
+	case params.Auth.APIKeyCredentials != nil:
+		user, err = h.authenticate(
+			params.Auth.APIKeyCredentials.Username,
+			params.Auth.APIKeyCredentials.APIKey,
+			params.Auth.TenantID,
+			params.Auth.TenantName,
+		)
+	case params.Auth.Token != nil:
+		method = "token"
+		user, parent, err = h.retoken(&v3AuthToken{ID: params.Auth.Token.ID})
+		if user != nil && err == nil && (params.Auth.TenantID != "" || params.Auth.TenantName != "") {
+			user, err = h.scoped(user, &v3Scope{
+				Project: &v3Project{
+					ID:     params.Auth.TenantID,
+					Name:   params.Auth.TenantName,
+					Domain: &v3Domain{Name: h.defaultDomain},
+				},
+			})
+		}
+	default:
+		return weberror.New(http.StatusBadRequest, swift.BadRequest.Text)
+	}
+	if err != nil {
+		return weberror.New(http.StatusBadRequest, swift.BadRequest.Text)
+	}
+	if user == nil {
+		return weberror.New(http.StatusUnauthorized, swift.AuthorizationFailed.Text)
+	}
+
+	token, err := h.issue(user, parent, method)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	project, err := h.db.FindProject(token.ProjectID)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	// Render response
+	url := h.storageURL(c, project)
+
+	var payload keystone2response
+	payload.Access.Token = keystone2token{
+		ID:       token.Key,
+		IssuedAt: token.CreatedAt.Format(time.RFC3339),
+		Expires:  token.ExpiresAt.Format(time.RFC3339),
+		Tenant:   keystone2entity{ID: project.ID, Name: project.Name},
+	}
+	payload.Access.User = keystone2entity{ID: user.ID, Name: user.Name}
+	payload.Access.ServiceCatalog = []keystone2catalog{
+		{
+			Name: "swift",
+			Type: "object-store",
+			Endpoints: []keystone2endpoint{
+				{
+					Region:      "RegionOne",
+					TenantID:    project.ID,
+					PublicURL:   url,
+					InternalURL: url,
+					AdminURL:    url,
+				},
+			},
+		},
+	}
+	return c.JSON(http.StatusOK, payload)
+}
+
+//
+//
+//
+//
+// Params
+//
+//
+//
+//
+
+// V2 Authentication request
+// http://docs.openstack.org/api/openstack-identity-service/2.0/content/POST_authenticate_v2.0_tokens_.html
+type keystone2params struct {
+	Auth struct {
+		PasswordCredentials *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"passwordCredentials,omitempty"`
+		APIKeyCredentials *struct {
+			Username string `json:"username"`
+			APIKey   string `json:"apiKey"`
+		} `json:"RAX-KSKEY:apiKeyCredentials,omitempty"`
+		Token *struct {
+			ID string `json:"id"`
+		} `json:"token,omitempty"`
+		TenantName string `json:"tenantName,omitempty"`
+		TenantID   string `json:"tenantId,omitempty"`
+	} `json:"auth"`
+}
+
+//
+// Response
+//
+
+type keystone2response struct {
+	Access struct {
+		Token          keystone2token     `json:"token"`
+		ServiceCatalog []keystone2catalog `json:"serviceCatalog"`
+		User           keystone2entity    `json:"user"`
+	} `json:"access"`
+}
+
+type keystone2token struct {
+	ID       string          `json:"id"`
+	IssuedAt string          `json:"issued_at"`
+	Expires  string          `json:"expires"`
+	Tenant   keystone2entity `json:"tenant"`
+}
+
+type keystone2entity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type keystone2catalog struct {
+	Name      string              `json:"name"`
+	Type      string              `json:"type"`
+	Endpoints []keystone2endpoint `json:"endpoints"`
+}
+
+type keystone2endpoint struct {
+	Region      string `json:"region"`
+	TenantID    string `json:"tenantId"`
+	PublicURL   string `json:"publicURL"`
+	InternalURL string `json:"internalURL"`
+	AdminURL    string `json:"adminURL"`
+}
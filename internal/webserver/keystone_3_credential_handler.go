@@ -0,0 +1,173 @@
+package webserver
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+	"github.com/ncw/swift/v2"
+)
+
+// CreateCredential creates an application credential for the user.
+func (h *keystone3) CreateCredential(c echo.Context) error {
+	c.Set("handler_method", "keystone3.CreateCredential")
+
+	user, token, err := h.owner(c)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(token.Methods, "application_credential") {
+		// The credentials are restricted, they can not create other credentials.
+		return weberror.New(http.StatusForbidden, swift.Forbidden.Text)
+	}
+
+	// Filter params
+	var params keystone3credentialparams
+	if err := c.Bind(&params); err != nil || params.Credential.Name == "" {
+		return weberror.New(http.StatusBadRequest, swift.BadRequest.Text)
+	}
+
+	_, err = h.db.FindApplicationCredentialByName(user.ID, params.Credential.Name)
+	if err == nil {
+		return weberror.New(http.StatusConflict, "Application credential already exists")
+	}
+	if !h.db.IsNotFound(err) {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	//
+
+	credential := &model.ApplicationCredential{
+		Name:        params.Credential.Name,
+		Description: params.Credential.Description,
+	}
+	if params.Credential.ExpiresAt != "" {
+		credential.ExpiresAt, err = time.Parse(time.RFC3339, params.Credential.ExpiresAt)
+		if err != nil {
+			return weberror.New(http.StatusBadRequest, "expires_at: "+err.Error())
+		}
+	}
+
+	secret, err := database.RegisterApplicationCredential(h.db, user, credential, params.Credential.Secret)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	//
+
+	payload := h.renderCredential(user, credential)
+	payload.Secret = secret
+	return c.JSON(http.StatusCreated, echo.Map{
+		"application_credential": payload,
+	})
+}
+
+// ListCredentials lists the application credentials of the user.
+func (h *keystone3) ListCredentials(c echo.Context) error {
+	c.Set("handler_method", "keystone3.ListCredentials")
+
+	user, _, err := h.owner(c)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := h.db.ListApplicationCredentials(user.ID)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	payload := make([]ApplicationCredential, 0, len(credentials))
+	for _, credential := range credentials {
+		payload = append(payload, h.renderCredential(user, credential))
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"application_credentials": payload,
+	})
+}
+
+// DeleteCredential deletes an application credential of the user.
+func (h *keystone3) DeleteCredential(c echo.Context) error {
+	c.Set("handler_method", "keystone3.DeleteCredential")
+
+	user, _, err := h.owner(c)
+	if err != nil {
+		return err
+	}
+
+	credential, err := h.db.FindApplicationCredential(c.Param("id"))
+	if err != nil || credential.UserID != user.ID {
+		if err == nil || h.db.IsNotFound(err) {
+			return weberror.New(http.StatusNotFound, "Application credential not found")
+		}
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if err = h.db.DeleteApplicationCredential(credential.ID); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// owner returns the user of the request's path and the X-Auth-Token if it belongs to the user.
+func (h *keystone3) owner(c echo.Context) (*model.User, *model.Token, error) {
+	token, err := h.token(c.Request().Header.Get("X-Auth-Token"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token.UserID != c.Param("user_id") {
+		return nil, nil, weberror.New(http.StatusForbidden, swift.Forbidden.Text)
+	}
+
+	user, err := h.db.FindUser(token.UserID)
+	if err != nil {
+		return nil, nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return user, token, nil
+}
+
+func (h *keystone3) renderCredential(user *model.User, credential *model.ApplicationCredential) ApplicationCredential {
+	payload := ApplicationCredential{
+		ID:          credential.ID,
+		Name:        credential.Name,
+		Description: credential.Description,
+		UserID:      user.ID,
+		ProjectID:   user.ProjectID,
+	}
+	if !credential.ExpiresAt.IsZero() {
+		payload.ExpiresAt = credential.ExpiresAt.Format(time.RFC3339)
+	}
+	return payload
+}
+
+//
+// Params
+//
+
+type keystone3credentialparams struct {
+	Credential struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Secret      string `json:"secret"`
+		ExpiresAt   string `json:"expires_at"`
+	} `json:"application_credential"`
+}
+
+//
+// Response
+//
+
+type ApplicationCredential struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Secret      string `json:"secret,omitempty"`
+	UserID      string `json:"user_id"`
+	ProjectID   string `json:"project_id"`
+	ExpiresAt   string `json:"expires_at,omitempty"`
+}
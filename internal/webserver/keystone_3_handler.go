@@ -7,17 +7,15 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/mdouchement/logger"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
 	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
 	"github.com/ncw/swift/v2"
 )
 
 type keystone3 struct {
+	identity
 	logger logger.Logger
-
-	tenant   string
-	domain   string
-	username string
-	password string
 }
 
 func (h *keystone3) Authenticate(c echo.Context) error {
@@ -30,52 +28,283 @@ func (h *keystone3) Authenticate(c echo.Context) error {
 	}
 
 	// Authorization
-	authorized, err := h.authorize(params)
+	user, parent, err := h.authorize(params)
 	if err != nil {
 		return weberror.New(http.StatusBadRequest, swift.BadRequest.Text)
 	}
-	if !authorized {
+	if user == nil {
 		return weberror.New(http.StatusUnauthorized, swift.AuthorizationFailed.Text)
 	}
 
+	token, err := h.issue(user, parent, params.Auth.Identity.Methods...)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
 	// Render response
-	c.Response().Header().Set("X-Subject-Token", CraftToken(h.username))
-	return c.JSON(http.StatusCreated, keystone3reponse{
-		Token: Token{
-			IssuedAt:  time.Now().Format(time.RFC3339),
-			ExpiresAt: time.Now().AddDate(0, 1, 0).Format(time.RFC3339),
-			Catalog: []Catalog{
+	payload, err := h.render(c, token, true)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set("X-Subject-Token", token.Key)
+	return c.JSON(http.StatusCreated, keystone3reponse{Token: payload})
+}
+
+// Validate checks the X-Subject-Token and renders its details.
+func (h *keystone3) Validate(c echo.Context) error {
+	c.Set("handler_method", "keystone3.Validate")
+
+	token, err := h.subject(c, false)
+	if err != nil {
+		return err
+	}
+
+	//
+
+	payload, err := h.render(c, token, !c.QueryParams().Has("nocatalog"))
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	c.Response().Header().Set("X-Subject-Token", token.Key)
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(http.StatusOK)
+	}
+	return c.JSON(http.StatusOK, keystone3reponse{Token: payload})
+}
+
+// Revoke invalidates the X-Subject-Token.
+func (h *keystone3) Revoke(c echo.Context) error {
+	c.Set("handler_method", "keystone3.Revoke")
+
+	token, err := h.subject(c, true)
+	if err != nil {
+		return err
+	}
+
+	//
+
+	if err = h.db.DeleteToken(token.ID); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// subject returns the X-Subject-Token checked with any valid X-Auth-Token.
+// When owned is true, the X-Auth-Token must belong to the user of the X-Subject-Token.
+func (h *keystone3) subject(c echo.Context, owned bool) (*model.Token, error) {
+	auth, err := h.token(c.Request().Header.Get("X-Auth-Token"))
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := h.token(c.Request().Header.Get("X-Subject-Token"))
+	if err != nil {
+		return nil, weberror.New(http.StatusNotFound, "Token not found")
+	}
+
+	if owned && token.UserID != auth.UserID {
+		return nil, weberror.New(http.StatusForbidden, swift.Forbidden.Text)
+	}
+	return token, nil
+}
+
+// Catalog renders the service catalog of the X-Auth-Token.
+func (h *keystone3) Catalog(c echo.Context) error {
+	c.Set("handler_method", "keystone3.Catalog")
+
+	token, err := h.token(c.Request().Header.Get("X-Auth-Token"))
+	if err != nil {
+		return err
+	}
+
+	project, err := h.db.FindProject(token.ProjectID)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"catalog": h.catalog(c, project),
+		"links": echo.Map{
+			"self": baseURL(c) + "/v3/auth/catalog",
+		},
+	})
+}
+
+// Discovery renders the Identity API version.
+func (h *keystone3) Discovery(c echo.Context) error {
+	c.Set("handler_method", "keystone3.Discovery")
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"version": echo.Map{
+			"id":      "v3.14",
+			"status":  "stable",
+			"updated": "2020-04-07T00:00:00Z",
+			"links": []echo.Map{
+				{"rel": "self", "href": baseURL(c) + "/v3/"},
+			},
+			"media-types": []echo.Map{
+				{"base": "application/json", "type": "application/vnd.openstack.identity-v3+json"},
+			},
+		},
+	})
+}
+
+// render returns the Token response of the given token.
+func (h *keystone3) render(c echo.Context, token *model.Token, catalog bool) (Token, error) {
+	user, err := h.db.FindUser(token.UserID)
+	if err != nil {
+		return Token{}, err
+	}
+
+	project, err := h.db.FindProject(token.ProjectID)
+	if err != nil {
+		return Token{}, err
+	}
+
+	domain, err := h.db.FindDomain(project.DomainID)
+	if err != nil {
+		return Token{}, err
+	}
+
+	//
+
+	payload := Token{
+		Methods:   token.Methods,
+		IssuedAt:  token.CreatedAt.Format(time.RFC3339),
+		ExpiresAt: token.ExpiresAt.Format(time.RFC3339),
+		User: TokenUser{
+			ID:     user.ID,
+			Name:   user.Name,
+			Domain: v3Domain{ID: domain.ID, Name: domain.Name},
+		},
+		Project: &TokenProject{
+			ID:     project.ID,
+			Name:   project.Name,
+			Domain: v3Domain{ID: domain.ID, Name: domain.Name},
+		},
+	}
+	if catalog {
+		payload.Catalog = h.catalog(c, project)
+	}
+	return payload, nil
+}
+
+// catalog returns the services exposed to the given project.
+func (h *keystone3) catalog(c echo.Context, project *model.Project) []Catalog {
+	return []Catalog{
+		{
+			Type: "object-store",
+			ID:   "050726f278654128aba89757ae25950c",
+			Name: "swift",
+			Endpoints: []Endpoint{
 				{
-					Type: "object-store",
-					ID:   "050726f278654128aba89757ae25950c",
-					Name: "swift",
-					Endpoints: []Endpoint{
-						{
-							ID:        "068d1b359ee84b438266cb736d81de97",
-							Interface: swift.EndpointTypePublic,
-							Region:    "RegionOne",
-							RegionID:  "RegionOne",
-							URL:       c.Scheme() + "://" + c.Request().Host + "/v1/AUTH_" + h.username,
-						},
-					},
+					ID:        "068d1b359ee84b438266cb736d81de97",
+					Interface: swift.EndpointTypePublic,
+					Region:    "RegionOne",
+					RegionID:  "RegionOne",
+					URL:       h.storageURL(c, project),
 				},
 			},
 		},
-	})
+	}
 }
 
-func (h *keystone3) authorize(params keystone3params) (bool, error) {
+// authorize returns the user matching the given params and, for the token and application credential methods,
+// the grant bounding its token. A nil user is returned when the credentials are not valid.
+func (h *keystone3) authorize(params keystone3params) (*model.User, *grant, error) {
 	for _, method := range params.Auth.Identity.Methods {
+		var user *model.User
+		var parent *grant
+		var err error
+
 		switch method {
 		case "password":
-			return params.Auth.Scope.Project.Domain.Name == h.domain &&
-				params.Auth.Scope.Project.Name == h.tenant &&
-				params.Auth.Identity.Password.User.Name == h.username &&
-				params.Auth.Identity.Password.User.Password == h.password, nil
+			user, err = h.password(params.Auth.Identity.Password, params.Auth.Scope)
+		case "token":
+			user, parent, err = h.retoken(params.Auth.Identity.Token)
+		case "application_credential":
+			if params.Auth.Scope != nil {
+				return nil, nil, errors.New("application credentials cannot request a scope")
+			}
+			return h.credential(params.Auth.Identity.ApplicationCredential)
+		default:
+			continue
+		}
+
+		if user == nil || err != nil {
+			return nil, nil, err
+		}
+		user, err = h.scoped(user, params.Auth.Scope)
+		return user, parent, err
+	}
+
+	return nil, nil, errors.New("unsupported Auth.Identity.Methods")
+}
+
+// password returns the user authenticated by its password.
+func (h *keystone3) password(params *v3AuthPassword, scope *v3Scope) (*model.User, error) {
+	if params == nil {
+		return nil, errors.New("missing Auth.Identity.Password")
+	}
+
+	// The user's domain is optional when the scope has one.
+	domain := params.User.Domain
+	if domain == nil && scope != nil && scope.Project != nil {
+		domain = scope.Project.Domain
+	}
+
+	user, err := h.user(params.User, domain)
+	if user == nil || err != nil {
+		return nil, err
+	}
+
+	if !database.CheckPassword(user, params.User.Password) {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// credential returns the user authenticated by one of its application credentials and the grant of the credential.
+func (h *keystone3) credential(params *v3AuthApplicationCredential) (*model.User, *grant, error) {
+	if params == nil {
+		return nil, nil, errors.New("missing Auth.Identity.ApplicationCredential")
+	}
+
+	var credential *model.ApplicationCredential
+	var user *model.User
+	var err error
+
+	switch {
+	case params.ID != "":
+		credential, err = h.db.FindApplicationCredential(params.ID)
+		if err != nil {
+			return nil, nil, h.notFound(err)
+		}
+
+		user, err = h.db.FindUser(credential.UserID)
+		if err != nil {
+			return nil, nil, h.notFound(err)
+		}
+	case params.Name != "" && params.User != nil:
+		user, err = h.user(*params.User, params.User.Domain)
+		if user == nil || err != nil {
+			return nil, nil, err
 		}
+
+		credential, err = h.db.FindApplicationCredentialByName(user.ID, params.Name)
+		if err != nil {
+			return nil, nil, h.notFound(err)
+		}
+	default:
+		return nil, nil, errors.New("missing application credential id or name and user")
 	}
 
-	return false, errors.New("unsupported Auth.Identity.Methods")
+	if credential.Expired() || !database.CheckSecret(credential, params.Secret) {
+		return nil, nil, nil
+	}
+	return user, &grant{expiresAt: credential.ExpiresAt}, nil
 }
 
 //
@@ -95,8 +324,10 @@ func (h *keystone3) authorize(params keystone3params) (bool, error) {
 type keystone3params struct {
 	Auth struct {
 		Identity struct {
-			Methods  []string        `json:"methods"`
-			Password *v3AuthPassword `json:"password,omitempty"`
+			Methods               []string                     `json:"methods"`
+			Password              *v3AuthPassword              `json:"password,omitempty"`
+			Token                 *v3AuthToken                 `json:"token,omitempty"`
+			ApplicationCredential *v3AuthApplicationCredential `json:"application_credential,omitempty"`
 		} `json:"identity"`
 		Scope *v3Scope `json:"scope,omitempty"`
 	} `json:"auth"`
@@ -111,9 +342,24 @@ type keystone3reponse struct {
 }
 
 type Token struct {
-	ExpiresAt string    `json:"expires_at"`
-	IssuedAt  string    `json:"issued_at"`
-	Catalog   []Catalog `json:"catalog"`
+	Methods   []string      `json:"methods"`
+	ExpiresAt string        `json:"expires_at"`
+	IssuedAt  string        `json:"issued_at"`
+	User      TokenUser     `json:"user"`
+	Project   *TokenProject `json:"project,omitempty"`
+	Catalog   []Catalog     `json:"catalog,omitempty"`
+}
+
+type TokenUser struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Domain v3Domain `json:"domain"`
+}
+
+type TokenProject struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Domain v3Domain `json:"domain"`
 }
 
 type Catalog struct {
@@ -170,3 +416,10 @@ type v3AuthToken struct {
 type v3AuthPassword struct {
 	User v3User `json:"user"`
 }
+
+type v3AuthApplicationCredential struct {
+	ID     string  `json:"id,omitempty"`
+	Name   string  `json:"name,omitempty"`
+	Secret string  `json:"secret,omitempty"`
+	User   *v3User `json:"user,omitempty"`
+}
@@ -0,0 +1,60 @@
+package webserver
+
+import (
+	"net/http"
+	"strings"
+)
+
+// objectHeaders are the system headers stored along with the object's metadata.
+var objectHeaders = []string{
+	"Cache-Control",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Expires",
+	"X-Robots-Tag",
+}
+
+// objectMetadata returns the object's metadata and system headers set by the request headers.
+func objectMetadata(header http.Header) map[string]string {
+	return metadata(header, "X-Object-Meta-", objectHeaders...)
+}
+
+// metadata returns the metadata set by the request headers starting with the prefix (e.g. X-Container-Meta-)
+// or matching one of the given keys. An empty value or its X-Remove-* header maps the key to an empty value
+// which removes the metadata.
+func metadata(header http.Header, prefix string, keys ...string) map[string]string {
+	metas := map[string]string{}
+	for key, values := range header {
+		if len(values) == 0 || !isMetadata(key, prefix, keys) {
+			continue
+		}
+		metas[key] = values[0]
+	}
+
+	// A removal header takes precedence over the header setting the same metadata.
+	for key, values := range header {
+		if len(values) == 0 || !strings.HasPrefix(key, "X-Remove-") {
+			continue
+		}
+
+		key = "X-" + strings.TrimPrefix(key, "X-Remove-")
+		if isMetadata(key, prefix, keys) {
+			metas[key] = ""
+		}
+	}
+	return metas
+}
+
+// isMetadata returns true when the header starts with the prefix or matches one of the keys.
+func isMetadata(key, prefix string, keys []string) bool {
+	if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+		return true
+	}
+	for _, k := range keys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
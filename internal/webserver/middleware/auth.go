@@ -2,19 +2,246 @@ package middleware
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/acl"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/xpath"
 	"github.com/ncw/swift/v2"
 )
 
-func Authenticate(token string) echo.MiddlewareFunc {
+// Authenticate checks that the request is allowed to access the requested account.
+// The account owner has a full access, other requests are checked against the TempURL or FormPost signatures or the container ACLs.
+// The account's project and the valid token, if any, are stored in the context under the `project' and `token' keys.
+func Authenticate(db database.Client) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) (err error) {
-			if c.Request().Header.Get("X-Auth-Token") != token {
-				return c.JSON(http.StatusUnauthorized, swift.AuthorizationFailed)
+			token, err := db.FindTokenByKey(c.Request().Header.Get("X-Auth-Token"))
+			if err != nil && !db.IsNotFound(err) {
+				return err
+			}
+			if err != nil || token.Expired() {
+				token = nil
+			}
+
+			project, err := db.FindProject(c.Param("account"))
+			if err != nil {
+				if !db.IsNotFound(err) {
+					return err
+				}
+				return denied(c, token)
+			}
+
+			c.Set("project", project)
+			if token != nil {
+				c.Set("token", token)
+			}
+
+			//
+
+			if c.QueryParams().Has("temp_url_sig") {
+				if tempURLReference(c) {
+					return c.JSON(http.StatusBadRequest, swift.BadRequest)
+				}
+
+				allowed, err := tempURL(db, c, project)
+				if err != nil {
+					return err
+				}
+				if !allowed {
+					return c.JSON(http.StatusUnauthorized, swift.AuthorizationFailed)
+				}
+				return next(c)
+			}
+
+			if token != nil && token.ProjectID == project.ID {
+				return next(c)
+			}
+
+			if IsFormPost(c.Request()) {
+				allowed, err := formPost(db, c, project)
+				if err != nil {
+					return err
+				}
+				if !allowed {
+					return c.JSON(http.StatusUnauthorized, swift.AuthorizationFailed)
+				}
+				return next(c)
+			}
+
+			allowed, err := authorize(db, c, project, token)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return denied(c, token)
 			}
 
 			return next(c)
 		}
 	}
 }
+
+// authorize checks the container ACLs for a request that is not made by the account owner.
+// Copies need the read access on the source and the write access on the destination.
+func authorize(db database.Client, c echo.Context, project *model.Project, token *model.Token) (bool, error) {
+	containername := c.Param("container")
+	if containername == "" {
+		return false, nil // Account operations are restricted to the owner.
+	}
+
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead:
+		return granted(db, c, project, token, containername, "X-Container-Read")
+	case http.MethodPut, http.MethodPost, http.MethodDelete:
+		if c.Param("object") == "" {
+			return false, nil // Container operations are restricted to the owner.
+		}
+
+		// The access to another account's source is checked by the copy.
+		if source := c.Request().Header.Get("X-Copy-From"); source != "" && c.Request().Method == http.MethodPut &&
+			!otherAccount(c, "X-Copy-From-Account") {
+			sourcename, _ := xpath.Entities(source)
+			ok, err := granted(db, c, project, token, sourcename, "X-Container-Read")
+			if !ok || err != nil {
+				return false, err
+			}
+		}
+		return granted(db, c, project, token, containername, "X-Container-Write")
+	case "COPY":
+		ok, err := granted(db, c, project, token, containername, "X-Container-Read")
+		if !ok || err != nil {
+			return false, err
+		}
+
+		// The access to another account's destination is checked by the copy.
+		if otherAccount(c, "Destination-Account") {
+			return true, nil
+		}
+		destinationname, _ := xpath.Entities(c.Request().Header.Get("Destination"))
+		return granted(db, c, project, token, destinationname, "X-Container-Write")
+	}
+	return false, nil
+}
+
+// otherAccount returns true when the given header of a copy names another account than the requested one.
+func otherAccount(c echo.Context, header string) bool {
+	account := c.Request().Header.Get(header)
+	if unescaped, err := url.PathUnescape(account); err == nil {
+		account = unescaped
+	}
+	return account != "" && account != "AUTH_"+c.Param("account")
+}
+
+// Readable checks that the request is allowed to read the objects of the container of the given project.
+// It is used to authorize the access to the targets of the symlinks.
+func Readable(db database.Client, c echo.Context, project *model.Project, containername string) (bool, error) {
+	token, _ := c.Get("token").(*model.Token)
+	if token != nil && token.ProjectID == project.ID {
+		return true, nil
+	}
+	return granted(db, c, project, token, containername, "X-Container-Read")
+}
+
+// Writable checks that the request is allowed to write the objects of the container of the given project.
+// It is used to authorize the destinations of the copies to another account.
+func Writable(db database.Client, c echo.Context, project *model.Project, containername string) (bool, error) {
+	token, _ := c.Get("token").(*model.Token)
+	if token != nil && token.ProjectID == project.ID {
+		return true, nil
+	}
+	return granted(db, c, project, token, containername, "X-Container-Write")
+}
+
+// granted checks the ACL stored in the given header of the container.
+func granted(db database.Client, c echo.Context, project *model.Project, token *model.Token, containername, header string) (bool, error) {
+	rules, err := containerACL(db, project, containername, header)
+	if err != nil {
+		return false, err
+	}
+
+	// Referrers can read objects but can only list the container with `.rlistings'.
+	if header == "X-Container-Read" && rules.Referrer(c.Request().Referer()) {
+		if c.Param("object") != "" || rules.Listings() {
+			return true, nil
+		}
+	}
+
+	if token == nil {
+		return false, nil
+	}
+
+	identities, err := identities(db, token, project)
+	if err != nil {
+		return false, err
+	}
+	return rules.Grants(identities...), nil
+}
+
+// containerACL returns the ACL stored in the given header of the container.
+func containerACL(db database.Client, project *model.Project, containername, header string) (acl.ACL, error) {
+	container, err := db.FindContainerByName(project.ID, containername)
+	if err != nil {
+		if db.IsNotFound(err) {
+			return acl.ACL{}, nil
+		}
+		return acl.ACL{}, err
+	}
+
+	metas, err := db.FindMeta(container.ID, "")
+	if err != nil && !db.IsNotFound(err) {
+		return acl.ACL{}, err
+	}
+
+	var latest *model.Meta
+	for _, meta := range metas {
+		if meta.Key != header {
+			continue
+		}
+		if latest == nil || meta.CreatedAt.After(*latest.CreatedAt) {
+			latest = meta
+		}
+	}
+
+	if latest == nil {
+		return acl.ACL{}, nil
+	}
+	return acl.Parse(latest.Value), nil
+}
+
+// identities returns all the ACL designations matching the owner of the token.
+// The project's name only designates it in the domain of the given owner of the ACL, where names are unique.
+func identities(db database.Client, token *model.Token, owner *model.Project) ([]string, error) {
+	user, err := db.FindUser(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := db.FindProject(token.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []string{project.ID, project.Account()}
+	if project.DomainID == owner.DomainID {
+		projects = append(projects, project.Name)
+	}
+
+	var identities []string
+	for _, p := range append(projects, "*") {
+		for _, u := range []string{user.ID, user.Name, "*"} {
+			identities = append(identities, p+":"+u)
+		}
+	}
+	return append(identities, projects...), nil
+}
+
+// denied renders 401 for anonymous requests and 403 for authenticated ones.
+func denied(c echo.Context, token *model.Token) error {
+	if token == nil {
+		return c.JSON(http.StatusUnauthorized, swift.AuthorizationFailed)
+	}
+	return c.JSON(http.StatusForbidden, swift.Forbidden)
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+)
+
+const (
+	// FormPostMemory is the amount of the multipart form kept in memory, the remaining files are stored on disk.
+	FormPostMemory = 32 << 20
+	// maxFormAttributes is the maximum size of the form's fields preceding its first file.
+	maxFormAttributes = 64 << 10
+)
+
+// IsFormPost returns true if the request is a FormPost upload.
+func IsFormPost(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+	mediatype, _, err := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+	return err == nil && mediatype == echo.MIMEMultipartForm
+}
+
+// formPost checks the signature and the expires fields of a FormPost upload.
+// They are read from the fields preceding the first file, the form is left to be parsed by the handler once they
+// are valid. The request's body is then limited to the signed max_file_size and max_file_count and the attributes are
+// stored in the context under the `formpost_attributes' key.
+//
+// https://docs.openstack.org/swift/latest/api/form_post_middleware.html
+func formPost(db database.Client, c echo.Context, project *model.Project) (bool, error) {
+	if c.Param("container") == "" {
+		return false, nil
+	}
+
+	attributes, err := formAttributes(c.Request())
+	if err != nil {
+		return false, nil
+	}
+
+	expires, err := strconv.ParseInt(attributes.Get("expires"), 10, 64)
+	if err != nil || !time.Unix(expires, 0).After(time.Now()) {
+		return false, nil
+	}
+
+	algorithm, signature, err := tempURLSignature(attributes.Get("signature"))
+	if err != nil {
+		return false, nil
+	}
+
+	//
+
+	keys, err := tempURLKeys(db, project, c.Param("container"))
+	if err != nil {
+		return false, err
+	}
+
+	formpath := path.Join("/v1", project.Account(), c.Param("container")) + "/" + c.Param("object")
+	if c.Param("object") == "" {
+		formpath = path.Join("/v1", project.Account(), c.Param("container"))
+	}
+
+	for _, key := range keys {
+		mac := hmac.New(algorithm, []byte(key))
+		fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s",
+			formpath,
+			attributes.Get("redirect"),
+			attributes.Get("max_file_size"),
+			attributes.Get("max_file_count"),
+			attributes.Get("expires"),
+		)
+
+		if hmac.Equal(mac.Sum(nil), signature) {
+			limitFormPost(c, attributes)
+			c.Set("formpost_attributes", attributes)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// limitFormPost limits the request's body to the signed files, each file is allowed the size of the attributes
+// for its part's headers.
+func limitFormPost(c echo.Context, attributes url.Values) {
+	maxsize, err := strconv.ParseInt(attributes.Get("max_file_size"), 10, 64)
+	if err != nil || maxsize < 0 {
+		maxsize = 0
+	}
+	maxcount, err := strconv.ParseInt(attributes.Get("max_file_count"), 10, 64)
+	if err != nil || maxcount < 0 {
+		maxcount = 0
+	}
+
+	limit := maxFormAttributes + maxcount*(maxsize+maxFormAttributes)
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, limit)
+}
+
+// formAttributes returns the fields preceding the first file of the form.
+// The read part of the body is put back in the request.
+func formAttributes(r *http.Request) (url.Values, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return nil, err
+	}
+
+	var read bytes.Buffer
+	body := r.Body
+	defer func() {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(&read, body), body}
+	}()
+
+	mr := multipart.NewReader(io.TeeReader(io.LimitReader(body, maxFormAttributes), &read), params["boundary"])
+	attributes := url.Values{}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return attributes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() != "" {
+			return attributes, nil
+		}
+
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		attributes.Add(part.FormName(), string(value))
+	}
+}
@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+)
+
+// TempURLKeys are the metadata holding the keys used to sign temporary URLs.
+var TempURLKeys = []string{
+	"X-Account-Meta-Temp-Url-Key",
+	"X-Account-Meta-Temp-Url-Key-2",
+	"X-Container-Meta-Temp-Url-Key",
+	"X-Container-Meta-Temp-Url-Key-2",
+}
+
+// tempURL checks the temp_url_sig and temp_url_expires query parameters of an object request.
+//
+// https://docs.openstack.org/swift/latest/api/temporary_url_middleware.html
+func tempURL(db database.Client, c echo.Context, project *model.Project) (bool, error) {
+	if c.Param("object") == "" {
+		return false, nil
+	}
+
+	var methods []string
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodPut:
+		methods = []string{c.Request().Method}
+	case http.MethodHead:
+		// A HEAD is allowed with a signature for GET, HEAD or PUT.
+		methods = []string{http.MethodHead, http.MethodGet, http.MethodPut}
+	default:
+		return false, nil
+	}
+
+	expires, err := tempURLExpires(c.QueryParam("temp_url_expires"))
+	if err != nil || !expires.After(time.Now()) {
+		return false, nil
+	}
+
+	algorithm, signature, err := tempURLSignature(c.QueryParam("temp_url_sig"))
+	if err != nil {
+		return false, nil
+	}
+
+	// Signed path
+	//
+
+	objectpath := path.Join("/v1", project.Account(), c.Param("container"), c.Param("object"))
+	if prefix, ok := c.QueryParams()["temp_url_prefix"]; ok {
+		if !strings.HasPrefix(c.Param("object"), prefix[0]) {
+			return false, nil
+		}
+		objectpath = "prefix:" + path.Join("/v1", project.Account(), c.Param("container")) + "/" + prefix[0]
+	}
+
+	var restriction string
+	if iprange := c.QueryParam("temp_url_ip_range"); iprange != "" {
+		if !ipInRange(c.RealIP(), iprange) {
+			return false, nil
+		}
+		restriction = "ip=" + iprange + "\n"
+	}
+
+	// Signature
+	//
+
+	keys, err := tempURLKeys(db, project, c.Param("container"))
+	if err != nil {
+		return false, err
+	}
+
+	for _, key := range keys {
+		for _, method := range methods {
+			mac := hmac.New(algorithm, []byte(key))
+			fmt.Fprintf(mac, "%s%s\n%s\n%s", restriction, method, c.QueryParam("temp_url_expires"), objectpath)
+
+			if hmac.Equal(mac.Sum(nil), signature) {
+				tempURLDisposition(c)
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// tempURLReferences are the headers and query parameters of a PUT referencing other objects than the signed one.
+var tempURLReferences = []string{
+	"X-Copy-From",
+	"X-Copy-From-Account",
+	"X-Symlink-Target",
+	"X-Symlink-Target-Account",
+	"X-Object-Manifest",
+}
+
+// tempURLReference returns true when the request references other objects than the signed one,
+// a temporary URL can not copy, link or assemble them.
+func tempURLReference(c echo.Context) bool {
+	for _, header := range tempURLReferences {
+		if c.Request().Header.Get(header) != "" {
+			return true
+		}
+	}
+	return c.QueryParam("multipart-manifest") == "put"
+}
+
+// tempURLExpires parses a UNIX timestamp or an ISO 8601 UTC date.
+func tempURLExpires(value string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	return time.Parse("2006-01-02T15:04:05Z", value)
+}
+
+// tempURLSignature parses an hexadecimal signature or a `<digest>:<base64 signature>' one.
+func tempURLSignature(value string) (func() hash.Hash, []byte, error) {
+	if digest, signature, found := strings.Cut(value, ":"); found {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(signature, "="))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		switch digest {
+		case "sha1":
+			return sha1.New, b, nil
+		case "sha256":
+			return sha256.New, b, nil
+		case "sha512":
+			return sha512.New, b, nil
+		}
+		return nil, nil, fmt.Errorf("unsupported digest %s", digest)
+	}
+
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch len(b) {
+	case sha1.Size:
+		return sha1.New, b, nil
+	case sha256.Size:
+		return sha256.New, b, nil
+	case sha512.Size:
+		return sha512.New, b, nil
+	}
+	return nil, nil, fmt.Errorf("unsupported signature length %d", len(b))
+}
+
+// tempURLKeys returns the account's and container's keys.
+func tempURLKeys(db database.Client, project *model.Project, containername string) ([]string, error) {
+	metas, err := db.FindMeta(project.ID, "")
+	if err != nil && !db.IsNotFound(err) {
+		return nil, err
+	}
+
+	container, err := db.FindContainerByName(project.ID, containername)
+	if err != nil && !db.IsNotFound(err) {
+		return nil, err
+	}
+	if err == nil {
+		cmetas, err := db.FindMeta(container.ID, "")
+		if err != nil && !db.IsNotFound(err) {
+			return nil, err
+		}
+		metas = append(metas, cmetas...)
+	}
+
+	var keys []string
+	for _, meta := range metas {
+		for _, key := range TempURLKeys {
+			if strings.EqualFold(meta.Key, key) && meta.Value != "" {
+				keys = append(keys, meta.Value)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// tempURLDisposition sets the Content-Disposition according to the filename and inline query parameters.
+// The header is set once the handler is done: the parameters override the object's stored Content-Disposition
+// which overrides the default attachment.
+func tempURLDisposition(c echo.Context) {
+	if c.Request().Method != http.MethodGet && c.Request().Method != http.MethodHead {
+		return
+	}
+
+	filename := c.QueryParam("filename")
+	_, inline := c.QueryParams()["inline"]
+
+	c.Response().Before(func() {
+		header := c.Response().Header()
+		switch {
+		case inline && filename == "":
+			header.Set("Content-Disposition", "inline")
+		case inline:
+			header.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+		case filename != "":
+			header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		case header.Get("Content-Disposition") == "":
+			header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(c.Param("object"))}))
+		}
+	})
+}
+
+// ipInRange returns true if the ip is the given IP or is in the given CIDR.
+func ipInRange(ip, iprange string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	if _, network, err := net.ParseCIDR(iprange); err == nil {
+		return network.Contains(addr)
+	}
+	return addr.Equal(net.ParseIP(iprange))
+}
@@ -1,9 +1,15 @@
 package webserver
 
 import (
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"strings"
+	"net/textproto"
+	"net/url"
+	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -11,21 +17,29 @@ import (
 	"github.com/mdouchement/openstackswift/internal/database"
 	"github.com/mdouchement/openstackswift/internal/model"
 	"github.com/mdouchement/openstackswift/internal/storage"
+	middlewarepkg "github.com/mdouchement/openstackswift/internal/webserver/middleware"
 	"github.com/mdouchement/openstackswift/internal/webserver/service"
 	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
 	"github.com/mdouchement/openstackswift/internal/xpath"
 	"github.com/ncw/swift/v2"
+	"github.com/pkg/errors"
 )
 
+// versionIDHeader is the response header of the version identifier of an object.
+const versionIDHeader = "X-Object-Version-Id"
+
 type object struct {
 	logger  logger.Logger
 	db		database.Client
 	storage storage.Backend
 }
 
-
+// setHeadersFromMeta sets the object's user metadata and system headers, the other stored headers are ignored.
 func (h *object) setHeadersFromMeta(c echo.Context, metas []*model.Meta) error {
 	for _, meta := range metas {
+		if !isMetadata(meta.Key, "X-Object-Meta-", objectHeaders) {
+			continue
+		}
 		c.Response().Header().Set(meta.Key, meta.Value)
 	}
 	return nil
@@ -34,7 +48,7 @@ func (h *object) setHeadersFromMeta(c echo.Context, metas []*model.Meta) error {
 func (h *object) Show(c echo.Context) error {
 	c.Set("handler_method", "object.Show")
 
-	container, manifest, object, metas, err := h.load(c.Param("container"), c.Param("object"))
+	container, manifest, object, metas, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
 
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
@@ -42,6 +56,23 @@ func (h *object) Show(c echo.Context) error {
 	if container == nil {
 		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
 	}
+
+	version, err := h.loadVersion(c, container, object)
+	if err != nil {
+		return err
+	}
+	if version != nil {
+		manifest, object = nil, version.Object()
+		if metas, err = h.versionMetas(container, version); err != nil {
+			return err
+		}
+	}
+	if object != nil && object.IsSymlink() && c.QueryParam("symlink") != "get" {
+		if container, manifest, object, metas, err = h.follow(c, object); err != nil {
+			return err
+		}
+	}
+
 	if manifest == nil && object == nil {
 		return weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
 	}
@@ -49,11 +80,17 @@ func (h *object) Show(c echo.Context) error {
 	//
 
 	if object == nil {
+		downloader, err := service.NewManifestDownloader(h.db, h.storage, container, manifest, h.segmentAuthorizer(c))
+		if err != nil {
+			return segmentError(err, http.StatusInternalServerError)
+		}
+
 		object = new(model.Object)
 		object.CreatedAt = manifest.CreatedAt
+		object.UpdatedAt = manifest.UpdatedAt
 		object.ContentType = manifest.ContentType
-		object.Size = manifest.Size
-		object.Checksum = manifest.Checksum
+		object.Size = downloader.Size()
+		object.Checksum = downloader.Checksum()
 	}
 
 	//
@@ -65,17 +102,26 @@ func (h *object) Show(c echo.Context) error {
 	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(object.CreatedAt.Unix(), 10))
 	c.Response().Header().Set("Content-Type", object.ContentType)
 	c.Response().Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
+	c.Response().Header().Set("Accept-Ranges", "bytes")
 	c.Response().Header().Set("Etag", object.Checksum)
+	c.Response().Header().Set("Last-Modified", object.UpdatedAt.UTC().Format(http.TimeFormat))
+	setLargeObjectHeaders(c, manifest)
+	setVersionHeader(c, object, version)
+	setSymlinkHeaders(c, object)
 	if !object.TTL.IsZero() {
 		c.Response().Header().Set("X-Delete-At", strconv.FormatInt(object.TTL.Unix(), 10))
 	}
-	return nil
+
+	if done, err := preconditions(c, object.Checksum, *object.UpdatedAt); done {
+		return err
+	}
+	return c.NoContent(http.StatusOK)
 }
 
 func (h *object) Download(c echo.Context) error {
 	c.Set("handler_method", "object.Download")
 
-	container, manifest, object, _, err := h.load(c.Param("container"), c.Param("object"))
+	container, manifest, object, metas, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
@@ -85,10 +131,35 @@ func (h *object) Download(c echo.Context) error {
 
 	//
 
+	version, err := h.loadVersion(c, container, object)
+	if err != nil {
+		return err
+	}
+	if version != nil {
+		manifest, object = nil, nil
+		if metas, err = h.versionMetas(container, version); err != nil {
+			return err
+		}
+	}
+	if object != nil && object.IsSymlink() && c.QueryParam("symlink") != "get" {
+		if container, manifest, object, metas, err = h.follow(c, object); err != nil {
+			return err
+		}
+	}
+
+	if manifest != nil && manifest.Static && c.QueryParam("multipart-manifest") == "get" {
+		return h.segments(c, manifest)
+	}
+
 	var downloader service.Downloader
 	switch {
+	case version != nil:
+		downloader = service.NewVersionDownloader(h.storage, container, version)
 	case manifest != nil:
-		downloader = service.NewManifestDownloader(h.db, h.storage, container, manifest)
+		downloader, err = service.NewManifestDownloader(h.db, h.storage, container, manifest, h.segmentAuthorizer(c))
+		if err != nil {
+			return segmentError(err, http.StatusInternalServerError)
+		}
 	case object != nil:
 		downloader = service.NewObjectDownloader(h.storage, container, object)
 	default:
@@ -97,24 +168,109 @@ func (h *object) Download(c echo.Context) error {
 
 	//
 
-	r, err := downloader.Stream()
-	if err != nil {
-		return weberror.New(http.StatusUnprocessableEntity, swift.ObjectCorrupted.Text)
-	}
-	defer r.Close()
-
-	c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(downloader.Size(), 10))
+	h.setHeadersFromMeta(c, metas)
+	c.Response().Header().Set("Accept-Ranges", "bytes")
 	c.Response().Header().Set("Etag", downloader.Checksum())
+	c.Response().Header().Set("Last-Modified", downloader.LastModified().UTC().Format(http.TimeFormat))
+	setLargeObjectHeaders(c, manifest)
+	setVersionHeader(c, object, version)
+	setSymlinkHeaders(c, object)
 	if object != nil && !object.TTL.IsZero() {
 		c.Response().Header().Set("X-Delete-At", strconv.FormatInt(object.TTL.Unix(), 10))
 	}
-	return c.Stream(http.StatusOK, downloader.ContentType(), r)
+
+	if done, err := preconditions(c, downloader.Checksum(), downloader.LastModified()); done {
+		return err
+	}
+
+	var ranges []byteRange
+	if rangeApplies(c, downloader.Checksum(), downloader.LastModified()) {
+		ranges, err = parseRange(c.Request().Header.Get("Range"), downloader.Size())
+	}
+	if err != nil {
+		c.Response().Header().Set("Content-Range", fmt.Sprintf("bytes */%d", downloader.Size()))
+		return weberror.New(http.StatusRequestedRangeNotSatisfiable, "Requested Range Not Satisfiable")
+	}
+
+	switch len(ranges) {
+	case 0:
+		r, err := downloader.Stream()
+		if err != nil {
+			return segmentError(err, http.StatusUnprocessableEntity)
+		}
+		defer r.Close()
+
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(downloader.Size(), 10))
+		return c.Stream(http.StatusOK, downloader.ContentType(), r)
+	case 1:
+		r, err := downloader.StreamRange(ranges[0].start, ranges[0].length)
+		if err != nil {
+			return segmentError(err, http.StatusUnprocessableEntity)
+		}
+		defer r.Close()
+
+		c.Response().Header().Set(echo.HeaderContentLength, strconv.FormatInt(ranges[0].length, 10))
+		c.Response().Header().Set("Content-Range", ranges[0].contentRange(downloader.Size()))
+		return c.Stream(http.StatusPartialContent, downloader.ContentType(), r)
+	}
+
+	return h.multirange(c, downloader, ranges)
+}
+
+// setLargeObjectHeaders sets the headers identifying a static or dynamic large object.
+func setLargeObjectHeaders(c echo.Context, manifest *model.Manifest) {
+	switch {
+	case manifest == nil:
+	case manifest.Static:
+		c.Response().Header().Set("X-Static-Large-Object", "True")
+	default:
+		c.Response().Header().Set("X-Object-Manifest", manifest.Prefix)
+	}
+}
+
+// setVersionHeader sets the version identifier of the requested version or of the versioned object.
+func setVersionHeader(c echo.Context, object *model.Object, version *model.Version) {
+	switch {
+	case version != nil:
+		c.Response().Header().Set(versionIDHeader, version.VersionID)
+	case object != nil && object.VersionID != "":
+		c.Response().Header().Set(versionIDHeader, object.VersionID)
+	}
+}
+
+// multirange streams the ranges of the content as a multipart/byteranges response.
+func (h *object) multirange(c echo.Context, downloader service.Downloader, ranges []byteRange) error {
+	mw := multipart.NewWriter(c.Response())
+	c.Response().Header().Set(echo.HeaderContentType, "multipart/byteranges; boundary="+mw.Boundary())
+	c.Response().WriteHeader(http.StatusPartialContent)
+
+	for _, br := range ranges {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			echo.HeaderContentType: {downloader.ContentType()},
+			"Content-Range":        {br.contentRange(downloader.Size())},
+		})
+		if err != nil {
+			return err
+		}
+
+		r, err := downloader.StreamRange(br.start, br.length)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
 }
 
 func (h *object) Update(c echo.Context) error {
 	c.Set("handler_method", "object.Update")
 
-	container, manifest, object, metas, err := h.load(c.Param("container"), c.Param("object"))
+	container, manifest, object, metas, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
@@ -138,17 +294,10 @@ func (h *object) Update(c echo.Context) error {
 
 	//
 
-	for key, values := range c.Request().Header {
-		if (!strings.HasPrefix(key, "X-Object-Meta-") && len(values) > 0 ) {
-			continue
-		}
-		h.logger.Debugf("object.Update: add meta %v: %v for key %v", key, values[0], c.Param("object"))
-		// set metadata
-		_, err := h.db.AddMeta(container.ID, c.Param("object"), key, values[0])
-		if err != nil {
-			return weberror.New(http.StatusInternalServerError, err.Error())
-		}
-		// if "" delete also ?
+	// A POST replaces all the object's metadata, the X-Remove-Object-Meta-* headers are implicit.
+	err = h.db.ReplaceMetas(container.ID, c.Param("object"), objectMetadata(c.Request().Header))
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 
 	//
@@ -165,21 +314,23 @@ func (h *object) Update(c echo.Context) error {
 func (h *object) Upload(c echo.Context) error {
 	c.Set("handler_method", "object.Upload")
 
-	container, _, object, _, err := h.load(c.Param("container"), c.Param("object"))
+	container, manifest, object, _, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 	if container == nil {
 		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
 	}
+	if err = createOnly(c, manifest != nil || object != nil); err != nil {
+		return err
+	}
 
 	//
 
-	if object == nil {
-		object = new(model.Object)
-	}
+	previous, object := object, overwriting(object)
 	object.ContainerID = container.ID
 	object.Key = c.Param("object")
+	object.SymlinkTarget, object.SymlinkAccount, object.SymlinkEtag = "", "", ""
 	object.ContentType = c.Request().Header.Get("Content-Type")
 	if object.ContentType == "" {
 		object.ContentType = echo.MIMEOctetStream
@@ -190,14 +341,22 @@ func (h *object) Upload(c echo.Context) error {
 	}
 
 	uploader := service.NewObjectUploader(h.storage, container, object)
+	uploader.Expect(c.Request().Header.Get("Etag"), c.Request().ContentLength)
+	archiveOnCommit(h.db, h.storage, container, uploader, previous, object)
 	err = uploader.Upload(c.Request().Body)
+	if err == swift.ObjectCorrupted || err == swift.BadRequest || err == service.ErrClientDisconnect {
+		return weberror.New(err.(*swift.Error).StatusCode, err.Error())
+	}
+	if _, ok := err.(weberror.HTTPCoder); ok {
+		return err
+	}
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 
 	//
 
-	if err := h.db.Save(object); err != nil {
+	if err := saveUpload(h.db, h.storage, container, object, objectMetadata(c.Request().Header)); err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 
@@ -208,19 +367,23 @@ func (h *object) Upload(c echo.Context) error {
 	c.Response().Header().Set("Content-Type", object.ContentType)
 	c.Response().Header().Set("Content-Length", strconv.FormatInt(object.Size, 10))
 	c.Response().Header().Set("Etag", object.Checksum)
+	setVersionHeader(c, object, nil)
 	return c.NoContent(http.StatusCreated)
 }
 
 func (h *object) Manifest(c echo.Context) error {
 	c.Set("handler_method", "object.Manifest")
 
-	container, manifest, _, _, err := h.load(c.Param("container"), c.Param("object"))
+	container, manifest, object, _, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 	if container == nil {
 		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
 	}
+	if err = createOnly(c, manifest != nil || object != nil); err != nil {
+		return err
+	}
 
 	//
 
@@ -230,11 +393,14 @@ func (h *object) Manifest(c echo.Context) error {
 	manifest.ContainerID = container.ID
 	manifest.Key = c.Param("object")
 	manifest.ContentType = c.Request().Header.Get("Content-Type")
+	if manifest.ContentType == "" {
+		manifest.ContentType = echo.MIMEOctetStream
+	}
 
 	mc := service.NewManifestCreation(h.db, h.storage, container, manifest)
 	err = mc.Create(c.Request().Header.Get("X-Object-Manifest"))
 	if err != nil {
-		return weberror.New(http.StatusInternalServerError, err.Error())
+		return weberror.New(http.StatusBadRequest, err.Error())
 	}
 
 	//
@@ -242,6 +408,9 @@ func (h *object) Manifest(c echo.Context) error {
 	if err := h.db.Save(manifest); err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
+	if err := h.db.ReplaceMetas(container.ID, manifest.Key, objectMetadata(c.Request().Header)); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
 
 	//
 
@@ -250,23 +419,46 @@ func (h *object) Manifest(c echo.Context) error {
 	return c.NoContent(http.StatusCreated)
 }
 
+// Copy copies the source object to the destination object, the objects can belong to different accounts.
+// The copy has the source's metadata merged with the request's ones, unless X-Fresh-Metadata is true.
+//
+// https://docs.openstack.org/swift/latest/api/object_api.html
 func (h *object) Copy(c echo.Context) error {
 	c.Set("handler_method", "object.Copy")
 
-	path := c.Get("object_source").(string)
-	cname, oname := xpath.Entities(path)
+	cname, oname := xpath.Entities(c.Get("object_source").(string))
+	if cname == "" || oname == "" {
+		return weberror.New(http.StatusPreconditionFailed, "X-Copy-From header must be of the form <container name>/<object name>")
+	}
+	dcname, doname := xpath.Entities(c.Get("object_destination").(string))
+	if dcname == "" || doname == "" {
+		return weberror.New(http.StatusPreconditionFailed, "Destination header must be of the form <container name>/<object name>")
+	}
+
+	source, err := h.copyAccount(c, c.Get("object_source_account").(string), cname, middlewarepkg.Readable)
+	if err != nil {
+		return err
+	}
+	destination, err := h.copyAccount(c, c.Get("object_destination_account").(string), dcname, middlewarepkg.Writable)
+	if err != nil {
+		return err
+	}
 
 	//
 
-	container, manifest, object, _, err := h.load(cname, oname)
+	container, manifest, object, _, err := h.load(source.ID, cname, oname)
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
 	if container == nil {
 		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
 	}
-
-	//
+	if object != nil && object.IsSymlink() && c.QueryParam("symlink") != "get" {
+		// The target is copied, the symlink itself is only copied with symlink=get.
+		if container, manifest, object, _, err = h.follow(c, object); err != nil {
+			return err
+		}
+	}
 
 	var copier service.Copier
 	switch {
@@ -280,29 +472,115 @@ func (h *object) Copy(c echo.Context) error {
 
 	//
 
-	path = c.Get("object_destination").(string)
-	cname, oname = xpath.Entities(path)
-
-	err = copier.Copy(cname, oname)
-	if err == swift.TooLargeObject || err == swift.ObjectCorrupted {
-		return weberror.New(err.(*swift.Error).StatusCode, err.Error())
-	}
+	dcontainer, dmanifest, dobject, _, err := h.load(destination.ID, dcname, doname)
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
+	if dcontainer == nil {
+		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
+	}
+	if err = createOnly(c, dmanifest != nil || dobject != nil); err != nil {
+		return err
+	}
+
+	header := c.Request().Header
+	opts := service.CopyOptions{
+		ContentType: header.Get("Content-Type"),
+		Metas:       objectMetadata(header),
+		Manifest:    manifest != nil && c.QueryParam("multipart-manifest") == "get",
+		Authorize:   h.segmentAuthorizer(c),
+	}
+	opts.FreshMetadata, _ = strconv.ParseBool(header.Get("X-Fresh-Metadata"))
+
+	ttl := new(model.Object)
+	if err = service.SetupObjectTTL(ttl, c.Request()); err != nil {
+		return weberror.New(http.StatusBadRequest, err.Error())
+	}
+	opts.TTL = ttl.TTL
+
+	if !opts.Manifest {
+		if opts.VersionID, err = archive(h.db, h.storage, dcontainer, dobject); err != nil {
+			return err
+		}
+	}
+
+	//
+
+	if err = copier.Copy(dcontainer, doname, opts); err != nil {
+		return segmentError(err, http.StatusInternalServerError)
+	}
 
 	//
 
 	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
 	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(copier.CreatedAt().Unix(), 10))
 	c.Response().Header().Set("Etag", copier.Checksum())
-	return nil
+	c.Response().Header().Set("X-Copied-From", path.Join(cname, oname))
+	if opts.VersionID != "" {
+		c.Response().Header().Set(versionIDHeader, opts.VersionID)
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+// copyAccount returns the project of the copy's source or destination account, the request's project by default.
+// The access to the container of another account is checked with the given authorization.
+func (h *object) copyAccount(c echo.Context, account, containername string, authorized func(database.Client, echo.Context, *model.Project, string) (bool, error)) (*model.Project, error) {
+	if unescaped, err := url.PathUnescape(account); err == nil {
+		account = unescaped
+	}
+	if account == "" || account == project(c).Account() {
+		return project(c), nil
+	}
+	if strings.Contains(account, "/") {
+		return nil, weberror.New(http.StatusPreconditionFailed, "Account name cannot contain slashes")
+	}
+
+	target, err := h.db.FindProject(strings.TrimPrefix(account, "AUTH_"))
+	if err != nil {
+		if h.db.IsNotFound(err) {
+			return nil, weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
+		}
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	allowed, err := authorized(h.db, c, target, containername)
+	if err != nil {
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	if !allowed {
+		return nil, weberror.New(http.StatusForbidden, swift.Forbidden.Text)
+	}
+	return target, nil
+}
+
+// segmentAuthorizer checks the read access to the segments of the large objects.
+// The owner and the TempURLs of the account can read all its segments.
+func (h *object) segmentAuthorizer(c echo.Context) service.Authorizer {
+	return func(pid, containername string) (bool, error) {
+		if pid == project(c).ID && c.QueryParams().Has("temp_url_sig") {
+			return true, nil // The signature has been checked by the authentication.
+		}
+
+		target, err := h.db.FindProject(pid)
+		if err != nil {
+			return false, err
+		}
+		return middlewarepkg.Readable(h.db, c, target, containername)
+	}
+}
+
+// segmentError renders the Swift errors of the large objects' segments, other errors are rendered with the given status.
+func segmentError(err error, status int) error {
+	if serr, ok := errors.Cause(err).(*swift.Error); ok {
+		return weberror.New(serr.StatusCode, serr.Text)
+	}
+	return weberror.New(status, err.Error())
 }
 
 func (h *object) Delete(c echo.Context) error {
 	c.Set("handler_method", "object.Delete")
 
-	container, manifest, object, _, err := h.load(c.Param("container"), c.Param("object"))
+	container, manifest, object, _, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
 	if err != nil {
 		return weberror.New(http.StatusInternalServerError, err.Error())
 	}
@@ -312,30 +590,209 @@ func (h *object) Delete(c echo.Context) error {
 
 	//
 
+	if manifest != nil && manifest.Static && c.QueryParam("multipart-manifest") == "delete" {
+		return h.deleteStaticManifest(c, container, manifest)
+	}
+
+	if vid := c.QueryParam("version-id"); vid != "" && manifest == nil {
+		history, err := service.NewVersionHistory(h.db, h.storage, container)
+		if err != nil {
+			return weberror.New(http.StatusInternalServerError, err.Error())
+		}
+
+		found, err := history.DeleteVersion(c.Param("object"), vid, object)
+		if err != nil {
+			return weberror.New(http.StatusInternalServerError, err.Error())
+		}
+		if !found {
+			return weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
+		}
+		c.Response().Header().Set(versionIDHeader, vid)
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	vid, err := remove(h.db, h.storage, container, c.Param("object"), manifest, object)
+	if err != nil {
+		return err
+	}
+
+	//
+
+	if vid != "" {
+		c.Response().Header().Set(versionIDHeader, vid)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// remove deletes the manifest or the object of the given key according to the legacy versioning or the object versioning of the container.
+// It returns the version identifier of the delete marker, empty when the object versioning is disabled.
+func remove(db database.Client, storage storage.Backend, container *model.Container, key string, manifest *model.Manifest, object *model.Object) (string, error) {
+	if manifest == nil {
+		versioning, err := versioning(db, storage, container)
+		if err != nil {
+			return "", err
+		}
+
+		if versioning != nil {
+			found, err := versioning.Delete(key, object)
+			if err != nil {
+				return "", weberror.New(http.StatusInternalServerError, err.Error())
+			}
+			if !found {
+				return "", weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
+			}
+			return "", nil
+		}
+
+		history, err := service.NewVersionHistory(db, storage, container)
+		if err != nil {
+			return "", weberror.New(http.StatusInternalServerError, err.Error())
+		}
+
+		if history.Enabled() || object != nil && object.VersionID != "" {
+			vid, found, err := history.Delete(key, object)
+			if err != nil {
+				return "", weberror.New(http.StatusInternalServerError, err.Error())
+			}
+			if !found {
+				return "", weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
+			}
+			return vid, nil
+		}
+	}
+
 	var destroyer service.Destroyer
 	switch {
 	case manifest != nil:
-		destroyer = service.NewManifestDestroyer(h.db, h.storage, container, manifest)
+		destroyer = service.NewManifestDestroyer(db, storage, container, manifest)
 	case object != nil:
-		destroyer = service.NewObjectDestroyer(h.db, h.storage, container, object)
+		destroyer = service.NewObjectDestroyer(db, storage, container, object)
 	default:
-		return weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
+		return "", weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
 	}
 
-	//
+	if err := destroyer.Destroy(); err != nil {
+		return "", weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return "", nil
+}
 
-	err = destroyer.Destroy()
+// versioning returns the legacy versioning of the container, nil when it is disabled.
+func versioning(db database.Client, storage storage.Backend, container *model.Container) (*service.Versioning, error) {
+	versioning, err := service.NewVersioning(db, storage, container)
+	if err == service.ErrVersionsContainer {
+		return nil, weberror.New(http.StatusPreconditionFailed, err.Error())
+	}
 	if err != nil {
-		return weberror.New(http.StatusInternalServerError, err.Error())
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
 	}
+	return versioning, nil
+}
 
-	//
+// archive archives the object before its overwrite according to the legacy versioning or the object versioning of the container.
+// It returns the version identifier of the overwriting object, empty when the object versioning is disabled.
+func archive(db database.Client, storage storage.Backend, container *model.Container, object *model.Object) (string, error) {
+	if object != nil {
+		versioning, err := versioning(db, storage, container)
+		if err != nil {
+			return "", err
+		}
 
-	return c.NoContent(http.StatusNoContent)
+		if versioning != nil {
+			if err = versioning.Archive(object); err != nil {
+				return "", weberror.New(http.StatusInternalServerError, err.Error())
+			}
+			return "", nil
+		}
+	}
+
+	history, err := service.NewVersionHistory(db, storage, container)
+	if err != nil {
+		return "", weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	vid, err := history.Archive(object)
+	if err != nil {
+		return "", weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return vid, nil
+}
+
+// overwriting returns the object overwriting the given one, a copy of it so the previous object can be archived once
+// the upload is verified. A new object is returned when the object does not exist.
+func overwriting(object *model.Object) *model.Object {
+	overwriting := new(model.Object)
+	if object != nil {
+		*overwriting = *object
+	}
+	return overwriting
+}
+
+// archiveOnCommit archives the previous object, nil when it does not exist, once the upload of the overwriting object
+// is verified. A failed upload leaves the versions of the container untouched.
+func archiveOnCommit(db database.Client, storage storage.Backend, container *model.Container, uploader *service.ObjectUploader, previous, object *model.Object) {
+	uploader.BeforeCommit(func() error {
+		vid, err := archive(db, storage, container, previous)
+		if err != nil {
+			return err
+		}
+		object.VersionID = vid
+		return nil
+	})
+}
+
+// saveUpload saves the uploaded object and replaces its metadata by the given ones.
+// The manifest of the same key, shadowed by the object, is removed.
+func saveUpload(db database.Client, storage storage.Backend, container *model.Container, object *model.Object, metas map[string]string) error {
+	if err := db.Save(object); err != nil {
+		return err
+	}
+
+	manifest, err := db.FindManifestByKey(container.ID, object.Key)
+	if err != nil && !db.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if err = service.NewManifestDestroyer(db, storage, container, manifest).Destroy(); err != nil {
+			return err
+		}
+	}
+
+	return db.ReplaceMetas(container.ID, object.Key, metas)
+}
+
+// loadVersion returns the version of the object requested by the version-id parameter, nil for the current version.
+func (h *object) loadVersion(c echo.Context, container *model.Container, object *model.Object) (*model.Version, error) {
+	vid := c.QueryParam("version-id")
+	if vid == "" || object != nil && object.Version().VersionID == vid {
+		return nil, nil
+	}
+
+	version, err := h.db.FindVersion(container.ID, c.Param("object"), vid)
+	if err != nil {
+		if h.db.IsNotFound(err) {
+			return nil, weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
+		}
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	if version.DeleteMarker {
+		c.Response().Header().Set(versionIDHeader, version.VersionID)
+		return nil, weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
+	}
+	return version, nil
+}
+
+// versionMetas returns the metadata of the previous version, they are stored under the version's ID.
+func (h *object) versionMetas(container *model.Container, version *model.Version) ([]*model.Meta, error) {
+	metas, err := h.db.FindMeta(container.ID, version.ID)
+	if err != nil && !h.db.IsNotFound(err) {
+		return nil, weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	return metas, nil
 }
 
-func (h *object) load(containername, objectname string) (*model.Container, *model.Manifest, *model.Object, []*model.Meta, error) {
-	container, err := h.db.FindContainerByName(containername)
+func (h *object) load(pid, containername, objectname string) (*model.Container, *model.Manifest, *model.Object, []*model.Meta, error) {
+	container, err := h.db.FindContainerByName(pid, containername)
 	if err != nil {
 		if h.db.IsNotFound(err) {
 			return nil, nil, nil, nil, nil
@@ -3,26 +3,35 @@ package serializer
 import (
 	"strings"
 
+	"github.com/mdouchement/openstackswift/internal/database"
 	"github.com/mdouchement/openstackswift/internal/model"
 )
 
-// TextContainers returns the text serialized form of the given models.
-func TextContainers(containers []*model.Container) string {
-	sl := make([]string, 0, len(containers))
+// TextContainers returns the text serialized form of the given entries.
+func TextContainers(entries []*database.ContainerEntry) string {
+	sl := make([]string, 0, len(entries))
 
-	for _, container := range containers {
-		sl = append(sl, container.Name)
+	for _, entry := range entries {
+		if entry.Container == nil {
+			sl = append(sl, entry.Subdir)
+			continue
+		}
+		sl = append(sl, entry.Container.Name)
 	}
 
-	return strings.Join(sl, "\n")
+	return strings.Join(sl, "\n") + "\n"
 }
 
-// Containers returns the serialized form of the given models.
-func Containers(containers []*model.Container) []map[string]interface{} {
-	sl := make([]map[string]interface{}, 0, len(containers))
+// Containers returns the serialized form of the given entries.
+func Containers(entries []*database.ContainerEntry) []map[string]interface{} {
+	sl := make([]map[string]interface{}, 0, len(entries))
 
-	for _, container := range containers {
-		sl = append(sl, Container(container))
+	for _, entry := range entries {
+		if entry.Container == nil {
+			sl = append(sl, Subdir(entry.Subdir))
+			continue
+		}
+		sl = append(sl, Container(entry.Container))
 	}
 
 	return sl
@@ -37,3 +46,10 @@ func Container(container *model.Container) map[string]interface{} {
 		"last_updated": container.UpdatedAt,
 	}
 }
+
+// Subdir returns the serialized form of a pseudo-directory.
+func Subdir(subdir string) map[string]interface{} {
+	return map[string]interface{}{
+		"subdir": subdir,
+	}
+}
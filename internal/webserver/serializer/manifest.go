@@ -0,0 +1,45 @@
+package serializer
+
+import (
+	"github.com/mdouchement/openstackswift/internal/model"
+)
+
+// Segments returns the serialized form of the segments of the given static large object.
+func Segments(manifest *model.Manifest) []map[string]interface{} {
+	sl := make([]map[string]interface{}, 0, len(manifest.Segments))
+
+	for _, segment := range manifest.Segments {
+		m := map[string]interface{}{
+			"name":          segment.Path(),
+			"content_type":  segment.ContentType,
+			"bytes":         segment.Size,
+			"last_modified": segment.LastModified,
+			"hash":          segment.Checksum,
+		}
+		if segment.Range != "" {
+			m["range"] = segment.Range
+		}
+		sl = append(sl, m)
+	}
+
+	return sl
+}
+
+// RawSegments returns the segments of the given static large object in the form used to upload its manifest.
+func RawSegments(manifest *model.Manifest) []map[string]interface{} {
+	sl := make([]map[string]interface{}, 0, len(manifest.Segments))
+
+	for _, segment := range manifest.Segments {
+		m := map[string]interface{}{
+			"path":       segment.Path(),
+			"etag":       segment.Checksum,
+			"size_bytes": segment.Size,
+		}
+		if segment.Range != "" {
+			m["range"] = segment.Range
+		}
+		sl = append(sl, m)
+	}
+
+	return sl
+}
@@ -3,30 +3,35 @@ package serializer
 import (
 	"strings"
 
+	"github.com/mdouchement/openstackswift/internal/database"
 	"github.com/mdouchement/openstackswift/internal/model"
 )
 
-// TextObjects returns the text serialized form of the given models.
-func TextObjects(objects []*model.Object, prefix string) string {
-	sl := make([]string, 0, len(objects))
+// TextObjects returns the text serialized form of the given entries.
+func TextObjects(entries []*database.ObjectEntry) string {
+	sl := make([]string, 0, len(entries))
 
-	for _, object := range objects {
-		if strings.HasPrefix(object.Key, prefix) {
-			sl = append(sl, object.Key)
+	for _, entry := range entries {
+		if entry.Object == nil {
+			sl = append(sl, entry.Subdir)
+			continue
 		}
+		sl = append(sl, entry.Object.Key)
 	}
 
-	return strings.Join(sl, "\n")
+	return strings.Join(sl, "\n") + "\n"
 }
 
-// Objects returns the serialized form of the given models.
-func Objects(objects []*model.Object, prefix string) []map[string]interface{} {
-	sl := make([]map[string]interface{}, 0, len(objects))
+// Objects returns the serialized form of the given entries.
+func Objects(entries []*database.ObjectEntry) []map[string]interface{} {
+	sl := make([]map[string]interface{}, 0, len(entries))
 
-	for _, object := range objects {
-		if strings.HasPrefix(object.Key, prefix) {
-			sl = append(sl, Object(object))
+	for _, entry := range entries {
+		if entry.Object == nil {
+			sl = append(sl, Subdir(entry.Subdir))
+			continue
 		}
+		sl = append(sl, Object(entry.Object))
 	}
 
 	return sl
@@ -34,11 +39,15 @@ func Objects(objects []*model.Object, prefix string) []map[string]interface{} {
 
 // Object returns the serialized form of the given model.
 func Object(object *model.Object) map[string]interface{} {
-	return map[string]interface{}{
+	m := map[string]interface{}{
 		"name":          object.Key,
 		"content_type":  object.ContentType,
 		"bytes":         object.Size,
 		"last_modified": object.UpdatedAt,
 		"hash":          object.Checksum,
 	}
+	if object.IsSymlink() {
+		m["symlink_path"] = object.SymlinkPath()
+	}
+	return m
 }
@@ -0,0 +1,45 @@
+package serializer
+
+import (
+	"strings"
+
+	"github.com/mdouchement/openstackswift/internal/database"
+)
+
+// TextVersions returns the text serialized form of the given entries.
+func TextVersions(entries []*database.VersionEntry) string {
+	sl := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.Version == nil {
+			sl = append(sl, entry.Subdir)
+			continue
+		}
+		sl = append(sl, entry.Version.Key)
+	}
+
+	return strings.Join(sl, "\n") + "\n"
+}
+
+// Versions returns the serialized form of the given entries.
+func Versions(entries []*database.VersionEntry) []map[string]interface{} {
+	sl := make([]map[string]interface{}, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.Version == nil {
+			sl = append(sl, Subdir(entry.Subdir))
+			continue
+		}
+		sl = append(sl, map[string]interface{}{
+			"name":          entry.Version.Key,
+			"content_type":  entry.Version.ContentType,
+			"bytes":         entry.Version.Size,
+			"last_modified": entry.Version.LastModified,
+			"hash":          entry.Version.Checksum,
+			"version_id":    entry.Version.VersionID,
+			"is_latest":     entry.Latest,
+		})
+	}
+
+	return sl
+}
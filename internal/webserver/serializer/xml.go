@@ -0,0 +1,149 @@
+package serializer
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/mdouchement/openstackswift/internal/database"
+)
+
+// xmlTimeFormat is the format of the XML listings' dates.
+const xmlTimeFormat = "2006-01-02T15:04:05.000000"
+
+// An XMLAccount is the XML serialized form of an account listing.
+type XMLAccount struct {
+	XMLName xml.Name      `xml:"account"`
+	Name    string        `xml:"name,attr"`
+	Entries []interface{} `xml:",any"`
+}
+
+// An XMLContainer is the XML serialized form of a container listing.
+type XMLContainer struct {
+	XMLName xml.Name      `xml:"container"`
+	Name    string        `xml:"name,attr"`
+	Entries []interface{} `xml:",any"`
+}
+
+type xmlContainerEntry struct {
+	XMLName      xml.Name `xml:"container"`
+	Name         string   `xml:"name"`
+	Count        int      `xml:"count"`
+	Bytes        int64    `xml:"bytes"`
+	LastModified string   `xml:"last_modified"`
+}
+
+type xmlObjectEntry struct {
+	XMLName      xml.Name `xml:"object"`
+	Name         string   `xml:"name"`
+	Hash         string   `xml:"hash"`
+	Bytes        int64    `xml:"bytes"`
+	ContentType  string   `xml:"content_type"`
+	LastModified string   `xml:"last_modified"`
+}
+
+type xmlVersionEntry struct {
+	XMLName      xml.Name `xml:"object"`
+	Name         string   `xml:"name"`
+	Hash         string   `xml:"hash"`
+	Bytes        int64    `xml:"bytes"`
+	ContentType  string   `xml:"content_type"`
+	LastModified string   `xml:"last_modified"`
+	VersionID    string   `xml:"version_id"`
+	IsLatest     bool     `xml:"is_latest"`
+}
+
+type xmlSubdirEntry struct {
+	XMLName xml.Name `xml:"subdir"`
+	Name    string   `xml:"name"`
+	Attr    string   `xml:"name,attr"`
+}
+
+// XMLContainers returns the XML serialized form of the given entries.
+func XMLContainers(account string, entries []*database.ContainerEntry) XMLAccount {
+	listing := XMLAccount{
+		Name:    account,
+		Entries: make([]interface{}, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		if entry.Container == nil {
+			listing.Entries = append(listing.Entries, xmlSubdir(entry.Subdir))
+			continue
+		}
+
+		listing.Entries = append(listing.Entries, xmlContainerEntry{
+			Name:         entry.Container.Name,
+			Count:        entry.Container.Count,
+			Bytes:        entry.Container.Bytes,
+			LastModified: xmlTime(entry.Container.UpdatedAt),
+		})
+	}
+
+	return listing
+}
+
+// XMLObjects returns the XML serialized form of the given entries.
+func XMLObjects(container string, entries []*database.ObjectEntry) XMLContainer {
+	listing := XMLContainer{
+		Name:    container,
+		Entries: make([]interface{}, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		if entry.Object == nil {
+			listing.Entries = append(listing.Entries, xmlSubdir(entry.Subdir))
+			continue
+		}
+
+		listing.Entries = append(listing.Entries, xmlObjectEntry{
+			Name:         entry.Object.Key,
+			Hash:         entry.Object.Checksum,
+			Bytes:        entry.Object.Size,
+			ContentType:  entry.Object.ContentType,
+			LastModified: xmlTime(entry.Object.UpdatedAt),
+		})
+	}
+
+	return listing
+}
+
+// XMLVersions returns the XML serialized form of the given entries.
+func XMLVersions(container string, entries []*database.VersionEntry) XMLContainer {
+	listing := XMLContainer{
+		Name:    container,
+		Entries: make([]interface{}, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		if entry.Version == nil {
+			listing.Entries = append(listing.Entries, xmlSubdir(entry.Subdir))
+			continue
+		}
+
+		listing.Entries = append(listing.Entries, xmlVersionEntry{
+			Name:         entry.Version.Key,
+			Hash:         entry.Version.Checksum,
+			Bytes:        entry.Version.Size,
+			ContentType:  entry.Version.ContentType,
+			LastModified: xmlTime(&entry.Version.LastModified),
+			VersionID:    entry.Version.VersionID,
+			IsLatest:     entry.Latest,
+		})
+	}
+
+	return listing
+}
+
+func xmlSubdir(subdir string) xmlSubdirEntry {
+	return xmlSubdirEntry{
+		Name: subdir,
+		Attr: subdir,
+	}
+}
+
+func xmlTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(xmlTimeFormat)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/pkg/errors"
+)
+
+// AdoptContainers moves the containers created before the accounts, without project, to the given project.
+// Their files are moved to the project's location in the storage backend.
+func AdoptContainers(database database.Client, storage storage.Backend, pid string) error {
+	containers, err := database.ListContainers("")
+	if err != nil {
+		return errors.Wrap(err, "AdoptContainers")
+	}
+
+	for _, container := range containers {
+		if _, err = database.FindContainerByName(pid, container.Name); err == nil {
+			return errors.Errorf("AdoptContainers: container %s already exists in the project", container.Name)
+		}
+		if !database.IsNotFound(err) {
+			return errors.Wrap(err, "AdoptContainers")
+		}
+
+		src := container.Path()
+		container.ProjectID = pid
+		if err = storage.Move(src, container.Path()); err != nil {
+			return errors.Wrap(err, "AdoptContainers")
+		}
+		if err = database.Save(container); err != nil {
+			return errors.Wrap(err, "AdoptContainers")
+		}
+	}
+	return nil
+}
@@ -14,11 +14,29 @@ import (
 )
 
 type Copier interface {
-	Copy(container, object string) error
+	Copy(container *model.Container, object string, opts CopyOptions) error
 	CreatedAt() time.Time
 	Checksum() string
 }
 
+// CopyOptions defines how the copy overrides the source.
+type CopyOptions struct {
+	// ContentType overrides the source's content type when not empty.
+	ContentType string
+	// Metas are merged into the source's metadata, an empty value removes the metadata.
+	Metas map[string]string
+	// FreshMetadata discards the source's metadata and expiration date.
+	FreshMetadata bool
+	// TTL overrides the source's expiration date when not zero.
+	TTL time.Time
+	// VersionID is the version identifier of the copy, empty when the object versioning is disabled.
+	VersionID string
+	// Manifest copies a large object's manifest itself instead of the concatenation of its segments.
+	Manifest bool
+	// Authorize checks the read access to the segments of a copied large object.
+	Authorize Authorizer
+}
+
 //
 //-----
 //
@@ -41,26 +59,32 @@ func NewObjectCopier(database database.Client, storage storage.Backend, containe
 	}
 }
 
-func (s *ObjectCopier) Copy(containername, objectname string) error {
-	container, err := s.database.FindContainerByName(containername)
+func (s *ObjectCopier) Copy(container *model.Container, objectname string, opts CopyOptions) error {
+	err := s.storage.Copy(s.container.Path(), s.object.Key, container.Path(), objectname)
 	if err != nil {
 		return errors.Wrap(err, "ObjectCopier")
 	}
 
-	err = s.storage.Copy(s.container.Name, s.object.Key, containername, objectname)
+	object, err := destination(s.database, container, objectname)
 	if err != nil {
 		return errors.Wrap(err, "ObjectCopier")
 	}
-
-	object := new(model.Object)
-	object.ContainerID = container.ID
-	object.Key = objectname
+	object.VersionID = opts.VersionID
 	object.ContentType = s.object.ContentType
 	object.Checksum = s.object.Checksum
 	object.Size = s.object.Size
+	object.TTL = s.object.TTL
+	object.SymlinkTarget = s.object.SymlinkTarget
+	object.SymlinkAccount = s.object.SymlinkAccount
+	object.SymlinkEtag = s.object.SymlinkEtag
+	opts.apply(object)
 
-	err = s.database.Save(object)
+	if err = s.database.Save(object); err != nil {
+		return errors.Wrap(err, "ObjectCopier")
+	}
 	s.createdAt = *object.CreatedAt
+
+	err = copyMetas(s.database, s.container.ID, s.object.Key, container.ID, objectname, opts)
 	return errors.Wrap(err, "ObjectCopier")
 }
 
@@ -87,7 +111,9 @@ type ManifestCopier struct {
 	storage   storage.Backend
 	container *model.Container
 	manifest  *model.Manifest
-	object    *model.Object
+
+	createdAt time.Time
+	checksum  string
 }
 
 // NewManifestCopier returns a new ManifestCopier.
@@ -97,7 +123,6 @@ func NewManifestCopier(database database.Client, storage storage.Backend, contai
 		storage:   storage,
 		container: container,
 		manifest:  manifest,
-		object:    new(model.Object),
 	}
 }
 
@@ -105,73 +130,153 @@ func NewManifestCopier(database database.Client, storage storage.Backend, contai
 // the new object is a normal, and not a segment, object.
 // If the total size of the source segment objects exceeds 5 GB, the COPY request fails.
 // However, you can make a duplicate of the manifest object and this new object can be larger than 5 GB.
-func (s *ManifestCopier) Copy(containername, objectname string) error {
-	if s.manifest.Size > 5<<30 {
-		return swift.TooLargeObject
+func (s *ManifestCopier) Copy(container *model.Container, objectname string, opts CopyOptions) error {
+	if opts.Manifest {
+		return s.duplicate(container, objectname, opts)
 	}
 
-	container, err := s.database.FindContainerByName(containername)
+	downloader, err := NewManifestDownloader(s.database, s.storage, s.container, s.manifest, opts.Authorize)
 	if err != nil {
 		return errors.Wrap(err, "ManifestCopier")
 	}
+	if downloader.Size() > 5<<30 {
+		return swift.TooLargeObject
+	}
 
 	//
 
-	s.object.ContainerID = container.ID
-	s.object.Key = objectname
-	s.object.ContentType = s.manifest.ContentType
+	object, err := destination(s.database, container, objectname)
+	if err != nil {
+		return errors.Wrap(err, "ManifestCopier")
+	}
+	object.VersionID = opts.VersionID
+	object.ContentType = s.manifest.ContentType
+	object.TTL = time.Time{}
+	opts.apply(object)
 
 	//
 
-	objects, err := s.database.FindObjectsByManifestID(s.manifest.ID)
+	r, err := downloader.Stream()
 	if err != nil {
 		return errors.Wrap(err, "ManifestCopier")
 	}
+	defer r.Close()
 
 	//
 
-	wc, err := s.storage.Writer(container.Name, s.object.Key)
+	wc, err := s.storage.Writer(container.Path(), object.Key)
 	if err != nil {
 		return errors.Wrap(err, "ManifestCopier")
 	}
-	defer wc.Close()
+	defer wc.Abort()
 
 	h := md5.New()
-	w := io.MultiWriter(h, wc)
+	object.Size, err = io.Copy(io.MultiWriter(h, wc), r)
+	if err != nil {
+		return errors.Wrap(err, "ManifestCopier")
+	}
+	object.Checksum = hex.EncodeToString(h.Sum(nil))
 
-	//
+	if object.Size != downloader.Size() {
+		return swift.ObjectCorrupted
+	}
 
-	for _, o := range objects {
-		scontainer, err := s.database.FindContainer(o.ContainerID)
-		if err != nil {
-			return errors.Wrap(err, "ManifestCopier")
-		}
+	if err = wc.Commit(); err != nil {
+		return errors.Wrap(err, "ManifestCopier")
+	}
 
-		r, err := s.storage.Reader(scontainer.Name, o.Key)
-		if err != nil {
-			return errors.Wrap(err, "ManifestCopier")
-		}
+	if err = s.database.Save(object); err != nil {
+		return errors.Wrap(err, "ManifestCopier")
+	}
+	s.createdAt, s.checksum = *object.CreatedAt, object.Checksum
 
-		n, err := io.Copy(w, r)
-		if err != nil {
-			return errors.Wrap(err, "ManifestCopier")
-		}
-		s.object.Size += n
+	err = copyMetas(s.database, s.container.ID, s.manifest.Key, container.ID, objectname, opts)
+	return errors.Wrap(err, "ManifestCopier")
+}
+
+// duplicate copies the manifest itself (multipart-manifest=get), the copy references the same segments.
+func (s *ManifestCopier) duplicate(container *model.Container, objectname string, opts CopyOptions) error {
+	manifest, err := s.database.FindManifestByKey(container.ID, objectname)
+	if err != nil && !s.database.IsNotFound(err) {
+		return errors.Wrap(err, "ManifestCopier")
+	}
+	if s.database.IsNotFound(err) {
+		manifest = &model.Manifest{ContainerID: container.ID, Key: objectname}
+	}
+	manifest.Size = s.manifest.Size
+	manifest.ContentType = s.manifest.ContentType
+	manifest.Checksum = s.manifest.Checksum
+	manifest.Prefix = s.manifest.Prefix
+	manifest.Static = s.manifest.Static
+	manifest.Segments = s.manifest.Segments
+	if opts.ContentType != "" {
+		manifest.ContentType = opts.ContentType
 	}
-	s.object.Checksum = hex.EncodeToString(h.Sum(nil))
 
-	if s.object.Size != s.manifest.Size {
-		return swift.ObjectCorrupted
+	if err = s.database.Save(manifest); err != nil {
+		return errors.Wrap(err, "ManifestCopier")
 	}
+	s.createdAt, s.checksum = *manifest.CreatedAt, manifest.Checksum
 
-	err = s.database.Save(s.object)
+	err = copyMetas(s.database, s.container.ID, s.manifest.Key, container.ID, objectname, opts)
 	return errors.Wrap(err, "ManifestCopier")
 }
 
 func (s *ManifestCopier) CreatedAt() time.Time {
-	return *s.object.CreatedAt
+	return s.createdAt
 }
 
 func (s *ManifestCopier) Checksum() string {
-	return s.object.Checksum
+	return s.checksum
+}
+
+//
+//-----
+//
+
+// apply overrides the copied object's content type and expiration date.
+func (opts CopyOptions) apply(object *model.Object) {
+	if opts.ContentType != "" {
+		object.ContentType = opts.ContentType
+	}
+	if opts.FreshMetadata {
+		object.TTL = time.Time{}
+	}
+	if !opts.TTL.IsZero() {
+		object.TTL = opts.TTL
+	}
+}
+
+// destination returns the overwritten object of the copy, a new object when it does not exist.
+// The overwritten object is no longer a symlink.
+func destination(database database.Client, container *model.Container, key string) (*model.Object, error) {
+	object, err := database.FindObjectByKey(container.ID, key)
+	if err != nil && !database.IsNotFound(err) {
+		return nil, err
+	}
+	if database.IsNotFound(err) {
+		object = &model.Object{ContainerID: container.ID, Key: key}
+	}
+	object.SymlinkTarget, object.SymlinkAccount, object.SymlinkEtag = "", "", ""
+	return object, nil
+}
+
+// copyMetas replaces the metadata of the destination object by the ones of the source object merged with the options' metadata.
+// The source's metadata are not copied when the options require fresh metadata.
+func copyMetas(database database.Client, scid, skey, dcid, dkey string, opts CopyOptions) error {
+	copied := map[string]string{}
+	if !opts.FreshMetadata {
+		metas, err := database.FindMeta(scid, skey)
+		if err != nil && !database.IsNotFound(err) {
+			return err
+		}
+		for _, meta := range metas {
+			copied[meta.Key] = meta.Value
+		}
+	}
+	for key, value := range opts.Metas {
+		copied[key] = value
+	}
+
+	return database.ReplaceMetas(dcid, dkey, copied)
 }
@@ -67,12 +67,12 @@ func NewObjectDestroyer(database database.Client, storage storage.Backend, conta
 }
 
 func (s *ObjectDestroyer) Destroy() error {
-	err := s.storage.Remove(s.container.Name, s.object.Key)
+	err := s.storage.Remove(s.container.Path(), s.object.Key)
 	if err != nil {
 		return errors.Wrap(err, "ObjectDestroyer storage")
 	}
 
-	err = s.database.DeleteAllMetas(s.container.ID, s.object.ID)
+	err = s.database.DeleteAllMetas(s.container.ID, s.object.Key)
 	if err != nil && !s.database.IsNotFound(err) {
 		return errors.Wrap(err, "ObjectDestroyer meta")
 	}
@@ -85,7 +85,8 @@ func (s *ObjectDestroyer) Destroy() error {
 //-----
 //
 
-// An ManifestDestroyer removes the segment objects from storage.
+// A ManifestDestroyer removes a manifest, its segments are kept like Swift does.
+// The segments of a static large object are removed by the StaticManifestDestroyer.
 type ManifestDestroyer struct {
 	database  database.Client
 	storage   storage.Backend
@@ -104,35 +105,115 @@ func NewManifestDestroyer(database database.Client, storage storage.Backend, con
 }
 
 func (s *ManifestDestroyer) Destroy() error {
-	objects, err := s.database.FindObjectsByManifestID(s.manifest.ID)
-	if err != nil {
-		return errors.Wrap(err, "ManifestDestroyer find manifest")
+	err := s.database.DeleteAllMetas(s.container.ID, s.manifest.Key)
+	if err != nil && !s.database.IsNotFound(err) {
+		return errors.Wrap(err, "ManifestDestroyer meta")
 	}
 
-	for _, object := range objects {
-		container, err := s.database.FindContainer(object.ContainerID)
-		if err != nil {
-			return errors.Wrap(err, "ManifestDestroyer find container")
-		}
+	//
 
-		err = s.storage.Remove(container.Name, object.Key)
-		if err != nil {
-			return errors.Wrap(err, "ManifestDestroyer storage")
+	err = s.database.DeleteManifest(s.manifest.ID)
+	return errors.Wrap(err, "ManifestDestroyer manifest")
+}
+
+//
+//-----
+//
+
+// A Deletion reports the outcome of the removal of several objects.
+type Deletion struct {
+	Deleted  int
+	NotFound int
+	// Errors are the paths and the statuses of the objects that could not be removed.
+	Errors [][2]string
+}
+
+// A StaticManifestDestroyer removes a static large object along with its segments.
+type StaticManifestDestroyer struct {
+	database  database.Client
+	storage   storage.Backend
+	container *model.Container
+	manifest  *model.Manifest
+	deletion  *Deletion
+	// depth is the nesting level of the manifest, 1 when it is not a segment.
+	depth int
+}
+
+// NewStaticManifestDestroyer returns a new StaticManifestDestroyer reporting the removed objects in the given deletion.
+func NewStaticManifestDestroyer(database database.Client, storage storage.Backend, container *model.Container, manifest *model.Manifest, deletion *Deletion) Destroyer {
+	return &StaticManifestDestroyer{
+		database:  database,
+		storage:   storage,
+		container: container,
+		manifest:  manifest,
+		deletion:  deletion,
+		depth:     1,
+	}
+}
+
+func (s *StaticManifestDestroyer) Destroy() error {
+	for _, segment := range s.manifest.Segments {
+		if err := s.destroy(segment); err != nil {
+			s.deletion.Errors = append(s.deletion.Errors, [2]string{segment.Path(), "500 Internal Server Error"})
 		}
+	}
 
-		err = s.database.DeleteObject(object.ID)
-		if err != nil {
-			return errors.Wrap(err, "ManifestDestroyer object")
+	err := NewManifestDestroyer(s.database, s.storage, s.container, s.manifest).Destroy()
+	if err != nil {
+		return errors.Wrap(err, "StaticManifestDestroyer")
+	}
+	s.deletion.Deleted++
+	return nil
+}
+
+// destroy removes the segment, nested static large objects are removed with their own segments up to MaxManifestDepth.
+func (s *StaticManifestDestroyer) destroy(segment *model.Segment) error {
+	container, err := s.database.FindContainerByName(s.container.ProjectID, segment.Container)
+	if err != nil {
+		if s.database.IsNotFound(err) {
+			s.deletion.NotFound++
+			return nil
 		}
+		return err
 	}
 
-	err = s.database.DeleteAllMetas(s.container.ID, s.manifest.ID)
-	if err != nil && !s.database.IsNotFound(err) {
-		return errors.Wrap(err, "ManifestDestroyer meta")
-    }
+	object, err := s.database.FindObjectByKey(container.ID, segment.Key)
+	if err == nil {
+		if err = NewObjectDestroyer(s.database, s.storage, container, object).Destroy(); err != nil {
+			return err
+		}
+		s.deletion.Deleted++
+		return nil
+	}
+	if !s.database.IsNotFound(err) {
+		return err
+	}
 
-	//
+	manifest, err := s.database.FindManifestByKey(container.ID, segment.Key)
+	if err != nil {
+		if s.database.IsNotFound(err) {
+			s.deletion.NotFound++
+			return nil
+		}
+		return err
+	}
 
-	err = s.database.DeleteManifest(s.manifest.ID)
-	return errors.Wrap(err, "ManifestDestroyer manifest")
+	if manifest.Static {
+		if s.depth >= MaxManifestDepth {
+			return ErrManifestDepth
+		}
+		return (&StaticManifestDestroyer{
+			database:  s.database,
+			storage:   s.storage,
+			container: container,
+			manifest:  manifest,
+			deletion:  s.deletion,
+			depth:     s.depth + 1,
+		}).Destroy()
+	}
+	if err = NewManifestDestroyer(s.database, s.storage, container, manifest).Destroy(); err != nil {
+		return err
+	}
+	s.deletion.Deleted++
+	return nil
 }
@@ -1,19 +1,49 @@
 package service
 
 import (
+	"crypto/md5"
+	"encoding/hex"
 	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/mdouchement/openstackswift/internal/database"
 	"github.com/mdouchement/openstackswift/internal/model"
 	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/mdouchement/openstackswift/internal/xpath"
+	"github.com/ncw/swift/v2"
 	"github.com/pkg/errors"
 )
 
+// An Authorizer checks that the request can read the segments stored in the given container of the project.
+// A nil Authorizer allows all the segments.
+type Authorizer func(pid, containername string) (bool, error)
+
+var (
+	// ErrSegmentConflict is returned when a segment of a static large object can not be read by the request.
+	ErrSegmentConflict = &swift.Error{StatusCode: http.StatusConflict, Text: "Conflict"}
+	// ErrManifestDepth is returned when static large objects are nested deeper than MaxManifestDepth.
+	ErrManifestDepth = &swift.Error{StatusCode: http.StatusConflict, Text: "Max recursion depth exceeded"}
+)
+
+// authorized checks the segments' container with the authorizer.
+func (authorize Authorizer) authorized(pid, containername string) (bool, error) {
+	if authorize == nil {
+		return true, nil
+	}
+	return authorize(pid, containername)
+}
+
 type Downloader interface {
 	Stream() (io.ReadCloser, error)
+	// StreamRange streams length bytes starting at the given offset.
+	StreamRange(offset, length int64) (io.ReadCloser, error)
 	ContentType() string
 	Size() int64
 	Checksum() string
+	LastModified() time.Time
 }
 
 //
@@ -21,21 +51,52 @@ type Downloader interface {
 //
 
 type ObjectDownloader struct {
-	storage   storage.Backend
-	container *model.Container
-	object    *model.Object
+	storage storage.Backend
+	object  *model.Object
+
+	// The location of the file in the storage backend.
+	path string
+	key  string
 }
 
 func NewObjectDownloader(storage storage.Backend, container *model.Container, object *model.Object) Downloader {
 	return &ObjectDownloader{
-		storage:   storage,
-		container: container,
-		object:    object,
+		storage: storage,
+		object:  object,
+		path:    container.Path(),
+		key:     object.Key,
+	}
+}
+
+// NewVersionDownloader returns a Downloader of a previous version of an object.
+func NewVersionDownloader(storage storage.Backend, container *model.Container, version *model.Version) Downloader {
+	return &ObjectDownloader{
+		storage: storage,
+		object:  version.Object(),
+		path:    container.VersionsPath(),
+		key:     version.ID,
 	}
 }
 
 func (s *ObjectDownloader) Stream() (io.ReadCloser, error) {
-	return s.storage.Reader(s.container.Name, s.object.Key)
+	return s.storage.Reader(s.path, s.key)
+}
+
+func (s *ObjectDownloader) StreamRange(offset, length int64) (io.ReadCloser, error) {
+	r, err := s.storage.Reader(s.path, s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = r.Seek(offset, io.SeekStart); err != nil {
+		r.Close()
+		return nil, errors.Wrap(err, "ObjectDownloader")
+	}
+
+	return &mreader{
+		Reader:  io.LimitReader(r, length),
+		closers: []io.Closer{r},
+	}, nil
 }
 
 func (s *ObjectDownloader) ContentType() string {
@@ -50,6 +111,10 @@ func (s *ObjectDownloader) Checksum() string {
 	return s.object.Checksum
 }
 
+func (s *ObjectDownloader) LastModified() time.Time {
+	return *s.object.UpdatedAt
+}
+
 //
 //-----
 //
@@ -59,59 +124,288 @@ type ManifestDownloader struct {
 	storage   storage.Backend
 	container *model.Container
 	manifest  *model.Manifest
+	authorize Authorizer
+	// depth is the nesting level of the manifest, 1 when it is not a segment.
+	depth int
+
+	// The segments of a dynamic large object, resolved when the downloader is created.
+	segments []part
+	size     int64
+	checksum string
+}
+
+// NewManifestDownloader returns a new ManifestDownloader.
+// The segments of a dynamic large object are resolved from the container's listing.
+// The segments' containers are checked with the authorizer, swift.Forbidden is returned for a dynamic large object
+// and ErrSegmentConflict for a static large object when a segment can not be read.
+func NewManifestDownloader(database database.Client, storage storage.Backend, container *model.Container, manifest *model.Manifest, authorize Authorizer) (Downloader, error) {
+	return newManifestDownloader(database, storage, container, manifest, authorize, 1)
 }
 
-func NewManifestDownloader(database database.Client, storage storage.Backend, container *model.Container, manifest *model.Manifest) Downloader {
-	return &ManifestDownloader{
+func newManifestDownloader(database database.Client, storage storage.Backend, container *model.Container, manifest *model.Manifest, authorize Authorizer, depth int) (Downloader, error) {
+	if depth > MaxManifestDepth {
+		return nil, ErrManifestDepth
+	}
+
+	s := &ManifestDownloader{
 		database:  database,
 		storage:   storage,
 		container: container,
 		manifest:  manifest,
+		authorize: authorize,
+		depth:     depth,
 	}
+
+	if manifest.Static {
+		for _, segment := range manifest.Segments {
+			allowed, err := authorize.authorized(container.ProjectID, segment.Container)
+			if err != nil {
+				return nil, errors.Wrap(err, "ManifestDownloader")
+			}
+			if !allowed {
+				return nil, ErrSegmentConflict
+			}
+		}
+	}
+
+	if !manifest.Static {
+		if containername, _ := xpath.Entities(manifest.Prefix); manifest.Prefix != "" {
+			allowed, err := authorize.authorized(container.ProjectID, containername)
+			if err != nil {
+				return nil, errors.Wrap(err, "ManifestDownloader")
+			}
+			if !allowed {
+				return nil, swift.Forbidden
+			}
+		}
+
+		objects, err := DynamicSegments(database, container.ProjectID, manifest)
+		if err != nil {
+			return nil, errors.Wrap(err, "ManifestDownloader")
+		}
+
+		h := md5.New()
+		for _, object := range objects {
+			ocontainer, err := database.FindContainer(object.ContainerID)
+			if err != nil {
+				return nil, errors.Wrap(err, "ManifestDownloader")
+			}
+
+			s.segments = append(s.segments, part{downloader: NewObjectDownloader(storage, ocontainer, object), length: object.Size})
+			s.size += object.Size
+			h.Write([]byte(object.Checksum))
+		}
+		s.checksum = `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+	}
+
+	return s, nil
 }
 
 func (s *ManifestDownloader) Stream() (io.ReadCloser, error) {
-	objects, err := s.database.FindObjectsByManifestID(s.manifest.ID)
+	return s.StreamRange(0, s.Size())
+}
+
+// StreamRange only opens the segments overlapping the range, one at a time while they are read.
+func (s *ManifestDownloader) StreamRange(offset, length int64) (io.ReadCloser, error) {
+	parts, err := s.parts()
 	if err != nil {
 		return nil, errors.Wrap(err, "ManifestDownloader")
 	}
 
-	reader := &mreader{}
-	var readers []io.Reader
-	for _, object := range objects {
-		container, err := s.database.FindContainer(object.ContainerID)
-		if err != nil {
-			return nil, errors.Wrap(err, "ManifestDownloader")
+	reader := &preader{}
+	for _, p := range parts {
+		if length <= 0 {
+			break
 		}
-
-		r, err := s.storage.Reader(container.Name, object.Key)
-		if err != nil {
-			return nil, errors.Wrap(err, "ManifestDownloader")
+		if offset >= p.length {
+			offset -= p.length // The segment is before the range.
+			continue
 		}
-		readers = append(readers, r)
-		reader.closers = append(reader.closers, r)
+
+		n := min(p.length-offset, length)
+		reader.parts = append(reader.parts, part{downloader: p.downloader, offset: p.offset + offset, length: n})
+
+		length -= n
+		offset = 0
 	}
 
-	reader.Reader = io.MultiReader(readers...)
 	return reader, nil
 }
 
+// A part is the range of a segment used by a manifest.
+type part struct {
+	downloader Downloader
+	offset     int64
+	length     int64
+}
+
+// parts returns the parts of the manifest, the listed segments of a static large object or
+// the resolved segments of a dynamic large object.
+func (s *ManifestDownloader) parts() ([]part, error) {
+	if s.manifest.Static {
+		parts := make([]part, 0, len(s.manifest.Segments))
+		for _, segment := range s.manifest.Segments {
+			downloader, err := findDownloader(s.database, s.storage, s.container.ProjectID, segment.Container, segment.Key, s.authorize, s.depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if downloader.Size() != segment.Size || downloader.Checksum() != segment.Checksum {
+				return nil, swift.ObjectCorrupted // The segment has changed since the manifest's creation.
+			}
+
+			parts = append(parts, part{downloader: downloader, offset: segment.Offset, length: segment.Length})
+		}
+		return parts, nil
+	}
+
+	return s.segments, nil
+}
+
 func (s *ManifestDownloader) ContentType() string {
 	return s.manifest.ContentType
 }
 
 func (s *ManifestDownloader) Size() int64 {
-	return s.manifest.Size
+	if s.manifest.Static {
+		return s.manifest.Size
+	}
+	return s.size
 }
 
+// Checksum returns the md5 of the segments' checksums, it is quoted for a dynamic large object.
 func (s *ManifestDownloader) Checksum() string {
-	return s.manifest.Checksum
+	if s.manifest.Static {
+		return s.manifest.Checksum
+	}
+	return s.checksum
+}
+
+func (s *ManifestDownloader) LastModified() time.Time {
+	return *s.manifest.UpdatedAt
+}
+
+//
+//-----
+//
+
+// FindDownloader returns the downloader of the object or manifest of the project with the given key.
+// The segments of a manifest are checked with the authorizer.
+func FindDownloader(database database.Client, storage storage.Backend, pid, containername, key string, authorize Authorizer) (Downloader, error) {
+	return findDownloader(database, storage, pid, containername, key, authorize, 1)
+}
+
+func findDownloader(database database.Client, storage storage.Backend, pid, containername, key string, authorize Authorizer, depth int) (Downloader, error) {
+	container, err := database.FindContainerByName(pid, containername)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := database.FindObjectByKey(container.ID, key)
+	if err == nil {
+		return NewObjectDownloader(storage, container, object), nil
+	}
+	if !database.IsNotFound(err) {
+		return nil, err
+	}
+
+	manifest, err := database.FindManifestByKey(container.ID, key)
+	if err != nil {
+		return nil, err
+	}
+	return newManifestDownloader(database, storage, container, manifest, authorize, depth)
+}
+
+// manifestDepth returns the nesting level of the downloaded object, 0 for an object.
+func manifestDepth(downloader Downloader) int {
+	s, ok := downloader.(*ManifestDownloader)
+	if !ok {
+		return 0
+	}
+	if s.manifest.Static {
+		return max(s.manifest.Depth, 1) // The manifests created before the depth was stored are at least nested once.
+	}
+	return 1
+}
+
+// DynamicSegments returns the segments of the dynamic large object, the objects of its prefix sorted by key.
+func DynamicSegments(database database.Client, pid string, manifest *model.Manifest) ([]*model.Object, error) {
+	if manifest.Prefix == "" {
+		// The manifests created before the resolution at read time have their segments linked.
+		objects, err := database.FindObjectsByManifestID(manifest.ID)
+		if err != nil && !database.IsNotFound(err) {
+			return nil, err
+		}
+		return objects, nil
+	}
+
+	p := manifest.Prefix
+	if up, err := url.PathUnescape(p); err == nil {
+		p = up
+	}
+
+	containername, prefix, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	container, err := database.FindContainerByName(pid, containername)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	objects, err := database.FindObjectsByContainerID(container.ID, -1, prefix)
+	if err != nil && !database.IsNotFound(err) {
+		return nil, err
+	}
+	return objects, nil
 }
 
 //
 //-----
 //
 
+// A preader reads the ranges of the parts one after the other, a part is opened once the previous one is read.
+type preader struct {
+	parts   []part
+	current io.ReadCloser
+}
+
+func (r *preader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+
+			rc, err := r.parts[0].downloader.StreamRange(r.parts[0].offset, r.parts[0].length)
+			if err != nil {
+				return 0, errors.Wrap(err, "ManifestDownloader")
+			}
+			r.parts = r.parts[1:]
+			r.current = rc
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *preader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
+
 type mreader struct {
 	io.Reader
 	closers []io.Closer
@@ -1,10 +1,6 @@
 package service
 
 import (
-	"crypto/md5"
-	"encoding/hex"
-	pathpkg "path"
-
 	"github.com/mdouchement/openstackswift/internal/database"
 	"github.com/mdouchement/openstackswift/internal/model"
 	"github.com/mdouchement/openstackswift/internal/storage"
@@ -12,7 +8,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-// A ManifestCreation is used to create manifest from uploaded objects.
+// A ManifestCreation is used to create dynamic large object from uploaded objects.
 type ManifestCreation struct {
 	database  database.Client
 	storage   storage.Backend
@@ -30,50 +26,16 @@ func NewManifestCreation(database database.Client, storage storage.Backend, cont
 	}
 }
 
-func (s *ManifestCreation) Create(path string) (err error) {
-	containername, basekey := xpath.Entities(path)
-
-	//
-
-	container := s.container
-	if container.Name != containername {
-		container, err = s.database.FindContainerByName(containername)
-		if err != nil {
-			return errors.Wrap(err, "X-Object-Manifest")
-		}
-	}
-
-	//
-
-	filenames, err := s.storage.FilenamesFrom(path)
-	if err != nil {
-		return errors.Wrap(err, "could not get filenames")
-	}
-
-	var size int64
-	h := md5.New()
-	for _, filename := range filenames {
-		object, err := s.database.FindObjectByKey(container.ID, pathpkg.Join(basekey, filename))
-		if err != nil {
-			return errors.Wrap(err, "X-Object-Manifest")
-		}
-
-		object.ManifestID = s.manifest.ID
-		if err = s.database.Save(object); err != nil {
-			return errors.Wrap(err, "X-Object-Manifest")
-		}
-
-		//
-
-		if s.manifest.ContentType == "" {
-			s.manifest.ContentType = object.ContentType
-		}
-
-		size += object.Size
-		h.Write([]byte(object.Checksum))
+// Create stores the `container/prefix' of the segments, they are resolved from the container's listing at read time.
+func (s *ManifestCreation) Create(path string) error {
+	if container, _ := xpath.Entities(path); container == "" {
+		return errors.New("X-Object-Manifest must be of the form container/prefix")
 	}
 
-	s.manifest.Size = size
-	s.manifest.Checksum = hex.EncodeToString(h.Sum(nil))
+	s.manifest.Prefix = path
+	s.manifest.Static = false
+	s.manifest.Segments = nil
+	s.manifest.Size = 0
+	s.manifest.Checksum = ""
 	return nil
 }
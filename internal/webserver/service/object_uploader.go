@@ -4,16 +4,25 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"io"
+	"strings"
 
 	"github.com/mdouchement/openstackswift/internal/model"
 	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/ncw/swift/v2"
 )
 
+// ErrClientDisconnect is returned when the request's body is shorter than its Content-Length.
+var ErrClientDisconnect = &swift.Error{StatusCode: 499, Text: "Client Disconnect"}
+
 // An ObjectUploader performs upload and metrics.
 type ObjectUploader struct {
 	storage   storage.Backend
 	container *model.Container
 	object    *model.Object
+
+	checksum string
+	size     int64
+	commit   func() error
 }
 
 // NewObjectUploader returns a new ObjectUploader.
@@ -22,26 +31,62 @@ func NewObjectUploader(storage storage.Backend, container *model.Container, obje
 		storage:   storage,
 		container: container,
 		object:    object,
+		size:      -1,
 	}
 }
 
+// Expect defines the checksum and the size the upload must match.
+// An empty checksum or a negative size are not verified.
+func (s *ObjectUploader) Expect(checksum string, size int64) {
+	s.checksum = strings.ToLower(strings.Trim(checksum, `"`))
+	s.size = size
+}
+
+// BeforeCommit defines a function called once the upload matches the expected checksum and size, before it replaces
+// the previous file. The upload is aborted when the function fails.
+func (s *ObjectUploader) BeforeCommit(fn func() error) {
+	s.commit = fn
+}
+
 // Upload performs the upload and update the inner Object.
+// The previous file is kept when the upload fails or does not match the expected checksum or size.
 func (s *ObjectUploader) Upload(r io.Reader) error {
-	wc, err := s.storage.Writer(s.container.Name, s.object.Key)
+	wc, err := s.storage.Writer(s.container.Path(), s.object.Key)
 	if err != nil {
 		return err
 	}
-	defer wc.Close()
+
+	defer wc.Abort()
 
 	h := md5.New()
 	w := io.MultiWriter(h, wc)
 
 	n, err := io.Copy(w, r)
+	if err == io.ErrUnexpectedEOF {
+		return ErrClientDisconnect
+	}
 	if err != nil {
 		return err
 	}
 
+	if s.size >= 0 && n != s.size {
+		return swift.BadRequest
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+	if s.checksum != "" && checksum != s.checksum {
+		return swift.ObjectCorrupted
+	}
+
+	if s.commit != nil {
+		if err = s.commit(); err != nil {
+			return err
+		}
+	}
+	if err = wc.Commit(); err != nil {
+		return err
+	}
+
 	s.object.Size = n
-	s.object.Checksum = hex.EncodeToString(h.Sum(nil))
+	s.object.Checksum = checksum
 	return nil
 }
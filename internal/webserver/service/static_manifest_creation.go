@@ -0,0 +1,220 @@
+package service
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/mdouchement/openstackswift/internal/xpath"
+	"github.com/ncw/swift/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxManifestSegments is the maximum number of segments of a static large object.
+	MaxManifestSegments = 1000
+	// MaxManifestSize is the maximum size of a static large object's manifest.
+	MaxManifestSize = 8 << 20
+	// MaxManifestDepth is the maximum nesting level of static large objects, Swift's max_slo_recursion_depth.
+	MaxManifestDepth = 10
+)
+
+// SegmentErrors are the validation errors of a static large object's manifest.
+type SegmentErrors []string
+
+// Error stringifies the errors.
+func (e SegmentErrors) Error() string {
+	return "Errors: " + strings.Join(e, ", ")
+}
+
+// A StaticManifestCreation is used to create static large object from uploaded segments.
+type StaticManifestCreation struct {
+	database  database.Client
+	storage   storage.Backend
+	container *model.Container
+	manifest  *model.Manifest
+	authorize Authorizer
+}
+
+// NewStaticManifestCreation returns a new StaticManifestCreation.
+// The segments' containers are checked with the authorizer.
+func NewStaticManifestCreation(database database.Client, storage storage.Backend, container *model.Container, manifest *model.Manifest, authorize Authorizer) *StaticManifestCreation {
+	return &StaticManifestCreation{
+		database:  database,
+		storage:   storage,
+		container: container,
+		manifest:  manifest,
+		authorize: authorize,
+	}
+}
+
+// Create validates the segments of the JSON manifest read from r against the stored objects.
+// The errors of all the invalid segments are returned as SegmentErrors.
+//
+// https://docs.openstack.org/swift/latest/api/large_objects.html#static-large-objects
+func (s *StaticManifestCreation) Create(r io.Reader) error {
+	var entries []struct {
+		Path  string  `json:"path"`
+		Etag  *string `json:"etag"`
+		Size  *int64  `json:"size_bytes"`
+		Range string  `json:"range"`
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r, MaxManifestSize+1))
+	if err != nil {
+		return err
+	}
+	if len(payload) > MaxManifestSize {
+		return SegmentErrors{"Manifest File > " + strconv.Itoa(MaxManifestSize) + " bytes"}
+	}
+	if err = json.Unmarshal(payload, &entries); err != nil {
+		return SegmentErrors{"Manifest must be valid JSON."}
+	}
+	if len(entries) == 0 {
+		return SegmentErrors{"Manifest must have at least one segment."}
+	}
+	if len(entries) > MaxManifestSegments {
+		return SegmentErrors{"Number of segments must be <= " + strconv.Itoa(MaxManifestSegments) + "."}
+	}
+
+	//
+
+	var errs SegmentErrors
+	var depth int
+	segments := make([]*model.Segment, 0, len(entries))
+	for i, entry := range entries {
+		containername, key := xpath.Entities(entry.Path)
+		if containername == "" || key == "" {
+			errs = append(errs, fmt.Sprintf("Index %d: path must be of the form /container/object.", i))
+			continue
+		}
+		if containername == s.container.Name && key == s.manifest.Key {
+			errs = append(errs, fmt.Sprintf("Index %d: manifest must not reference itself.", i))
+			continue
+		}
+
+		segment := &model.Segment{Container: containername, Key: key, Range: entry.Range}
+		path := segment.Path()
+
+		allowed, err := s.authorize.authorized(s.container.ProjectID, containername)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			errs = append(errs, path+", 403 Forbidden")
+			continue
+		}
+
+		downloader, err := FindDownloader(s.database, s.storage, s.container.ProjectID, containername, key, s.authorize)
+		if err != nil {
+			if s.database.IsNotFound(err) {
+				errs = append(errs, path+", 404 Not Found")
+				continue
+			}
+			if serr, ok := errors.Cause(err).(*swift.Error); ok {
+				errs = append(errs, path+", "+strconv.Itoa(serr.StatusCode)+" "+serr.Text)
+				continue
+			}
+			return err
+		}
+
+		if manifestDepth(downloader) >= MaxManifestDepth {
+			errs = append(errs, fmt.Sprintf("Index %d: max recursion depth exceeded.", i))
+			continue
+		}
+		depth = max(depth, manifestDepth(downloader))
+
+		if entry.Etag != nil && *entry.Etag != "" && strings.Trim(*entry.Etag, `"`) != strings.Trim(downloader.Checksum(), `"`) {
+			errs = append(errs, path+", Etag Mismatch")
+			continue
+		}
+		if entry.Size != nil && *entry.Size != downloader.Size() {
+			errs = append(errs, path+", Size Mismatch")
+			continue
+		}
+
+		segment.Checksum = downloader.Checksum()
+		segment.Size = downloader.Size()
+		segment.ContentType = downloader.ContentType()
+		segment.LastModified = downloader.LastModified()
+		segment.Length = segment.Size
+
+		if segment.Range != "" {
+			if segment.Offset, segment.Length, err = segmentRange(segment.Range, segment.Size); err != nil {
+				errs = append(errs, fmt.Sprintf("Index %d: %s", i, err))
+				continue
+			}
+		}
+		if segment.Length == 0 {
+			errs = append(errs, fmt.Sprintf("Index %d: too small; each segment must be at least 1 byte.", i))
+			continue
+		}
+
+		segments = append(segments, segment)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	//
+
+	var size int64
+	h := md5.New()
+	for _, segment := range segments {
+		size += segment.Length
+
+		h.Write([]byte(segment.Checksum))
+		if segment.Range != "" {
+			h.Write([]byte(":" + segment.Range + ";"))
+		}
+	}
+
+	s.manifest.Static = true
+	s.manifest.Segments = segments
+	s.manifest.Depth = depth + 1
+	s.manifest.Size = size
+	s.manifest.Checksum = hex.EncodeToString(h.Sum(nil))
+	if s.manifest.ContentType == "" {
+		s.manifest.ContentType = segments[0].ContentType
+	}
+	return nil
+}
+
+// segmentRange returns the offset and length of the single range `start-end', `start-' or `-suffix' of a segment.
+func segmentRange(value string, size int64) (offset, length int64, err error) {
+	first, last, found := strings.Cut(value, "-")
+	if !found || strings.Contains(value, ",") {
+		return 0, 0, fmt.Errorf("invalid range %q", value)
+	}
+
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, fmt.Errorf("invalid range %q", value)
+		}
+		n = min(n, size)
+		return size - n, n, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, fmt.Errorf("unsatisfiable range %q", value)
+	}
+
+	end := size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid range %q", value)
+		}
+		end = min(end, size-1)
+	}
+	return start, end - start + 1, nil
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/pkg/errors"
+)
+
+const (
+	// VersionsEnabled is the container's metadata enabling the object versioning.
+	VersionsEnabled = "X-Versions-Enabled"
+	// emptyChecksum is the checksum of the delete markers.
+	emptyChecksum = "d41d8cd98f00b204e9800998ecf8427e"
+)
+
+// NewVersionID returns a new version identifier, the identifiers are ordered by creation time.
+func NewVersionID() string {
+	t := time.Now()
+	return fmt.Sprintf("%010d.%05d", t.Unix(), t.Nanosecond()/10000)
+}
+
+// A VersionHistory keeps the previous versions of the objects of a container with object versioning enabled.
+//
+// https://docs.openstack.org/swift/latest/middleware.html#object-versioning
+type VersionHistory struct {
+	database  database.Client
+	storage   storage.Backend
+	container *model.Container
+	enabled   bool
+}
+
+// NewVersionHistory returns the VersionHistory of the container.
+func NewVersionHistory(database database.Client, storage storage.Backend, container *model.Container) (*VersionHistory, error) {
+	metas, err := database.FindMeta(container.ID, "")
+	if err != nil && !database.IsNotFound(err) {
+		return nil, errors.Wrap(err, "VersionHistory")
+	}
+
+	s := &VersionHistory{
+		database:  database,
+		storage:   storage,
+		container: container,
+	}
+	for _, meta := range metas {
+		if meta.Key == VersionsEnabled {
+			s.enabled = strings.EqualFold(meta.Value, "true")
+		}
+	}
+	return s, nil
+}
+
+// Enabled returns true when the new objects are versioned.
+func (s *VersionHistory) Enabled() bool {
+	return s.enabled
+}
+
+// Archive keeps the object as a previous version before its overwrite, the object is nil when it does not exist.
+// It returns the version identifier of the overwriting object, empty when the versioning is disabled.
+//
+// The null version of an object created while the versioning was disabled is only kept when the versioning is enabled.
+func (s *VersionHistory) Archive(object *model.Object) (string, error) {
+	if object != nil && (s.enabled || object.VersionID != "") {
+		if err := s.archive(object); err != nil {
+			return "", errors.Wrap(err, "VersionHistory archive")
+		}
+	}
+
+	if !s.enabled {
+		return "", nil
+	}
+	return NewVersionID(), nil
+}
+
+// Delete removes the current version of the object of the given key, the object is nil when it does not exist.
+// When the versioning is enabled, the object is kept as a previous version and a delete marker becomes the latest version.
+// It returns the version identifier of the delete marker and false when the object has not been found.
+func (s *VersionHistory) Delete(key string, object *model.Object) (string, bool, error) {
+	if object == nil {
+		return "", false, nil
+	}
+
+	vid, err := s.Archive(object)
+	if err != nil {
+		return "", false, err
+	}
+	if err = NewObjectDestroyer(s.database, s.storage, s.container, object).Destroy(); err != nil {
+		return "", false, errors.Wrap(err, "VersionHistory delete")
+	}
+	if vid == "" {
+		return "", true, nil
+	}
+
+	marker := &model.Version{
+		ContainerID:  s.container.ID,
+		Key:          key,
+		VersionID:    vid,
+		ContentType:  DeleteMarker,
+		Checksum:     emptyChecksum,
+		LastModified: time.Now(),
+		DeleteMarker: true,
+	}
+	return vid, true, errors.Wrap(s.database.Save(marker), "VersionHistory delete marker")
+}
+
+// DeleteVersion removes the given version of the object of the given key, the object is nil when it does not exist.
+// When the latest version is removed, the previous one becomes the current version unless it is a delete marker.
+// It returns false when the version has not been found.
+func (s *VersionHistory) DeleteVersion(key, vid string, object *model.Object) (bool, error) {
+	if object != nil && object.Version().VersionID == vid {
+		if err := NewObjectDestroyer(s.database, s.storage, s.container, object).Destroy(); err != nil {
+			return false, errors.Wrap(err, "VersionHistory delete version")
+		}
+		return true, s.restore(key)
+	}
+
+	versions, err := s.versions(key)
+	if err != nil {
+		return false, err
+	}
+
+	for i, version := range versions {
+		if version.VersionID != vid {
+			continue
+		}
+
+		if err = s.remove(version); err != nil {
+			return false, err
+		}
+		if i > 0 || object != nil {
+			return true, nil
+		}
+		return true, s.restore(key)
+	}
+	return false, nil
+}
+
+// restore makes the latest previous version of the object of the given key its current version, unless it is a delete marker.
+func (s *VersionHistory) restore(key string) error {
+	versions, err := s.versions(key)
+	if err != nil || len(versions) == 0 || versions[0].DeleteMarker {
+		return err
+	}
+	version := versions[0]
+
+	if err = s.storage.Copy(s.container.VersionsPath(), version.ID, s.container.Path(), key); err != nil {
+		return errors.Wrap(err, "VersionHistory restore")
+	}
+
+	if err = s.database.Save(version.Object()); err != nil {
+		return errors.Wrap(err, "VersionHistory restore")
+	}
+	if err = s.copyMetas(version.ID, key); err != nil {
+		return errors.Wrap(err, "VersionHistory restore")
+	}
+	return s.remove(version)
+}
+
+// archive copies the object into the versions of the container, replacing the version with the same identifier.
+func (s *VersionHistory) archive(object *model.Object) error {
+	version := object.Version()
+
+	previous, err := s.database.FindVersion(s.container.ID, version.Key, version.VersionID)
+	if err != nil && !s.database.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		version.Base = previous.Base
+	}
+
+	if err = s.database.Save(version); err != nil {
+		return err
+	}
+	if err = s.copyMetas(object.Key, version.ID); err != nil {
+		return err
+	}
+	return s.storage.Copy(s.container.Path(), object.Key, s.container.VersionsPath(), version.ID)
+}
+
+// copyMetas replaces the metadata of the destination key by the ones of the source key.
+// The metadata of a version are stored under its ID like its file.
+func (s *VersionHistory) copyMetas(src, dst string) error {
+	metas, err := s.database.FindMeta(s.container.ID, src)
+	if err != nil && !s.database.IsNotFound(err) {
+		return err
+	}
+
+	copied := map[string]string{}
+	for _, meta := range metas {
+		copied[meta.Key] = meta.Value
+	}
+	return s.database.ReplaceMetas(s.container.ID, dst, copied)
+}
+
+// remove deletes the version, its file and its metadata.
+func (s *VersionHistory) remove(version *model.Version) error {
+	if !version.DeleteMarker {
+		if err := s.storage.Remove(s.container.VersionsPath(), version.ID); err != nil {
+			return errors.Wrap(err, "VersionHistory remove")
+		}
+	}
+	if err := s.database.DeleteAllMetas(s.container.ID, version.ID); err != nil && !s.database.IsNotFound(err) {
+		return errors.Wrap(err, "VersionHistory remove")
+	}
+	return errors.Wrap(s.database.DeleteVersion(version.ID), "VersionHistory remove")
+}
+
+// versions returns the previous versions of the object of the given key, from the newest.
+func (s *VersionHistory) versions(key string) ([]*model.Version, error) {
+	versions, err := s.database.FindVersions(s.container.ID, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "VersionHistory find versions")
+	}
+
+	// The prefix also matches the longer keys.
+	filtered := versions[:0]
+	for _, version := range versions {
+		if version.Key == key {
+			filtered = append(filtered, version)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/ncw/swift/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// VersionsLocation is the container's metadata enabling the stack mode, a DELETE restores the previous version.
+	VersionsLocation = "X-Versions-Location"
+	// HistoryLocation is the container's metadata enabling the history mode, a DELETE archives the object followed by a delete marker.
+	HistoryLocation = "X-History-Location"
+	// DeleteMarker is the content type of the delete markers of the history mode.
+	DeleteMarker = "application/x-deleted;swift_versions_deleted=1"
+)
+
+// ErrVersionsContainer is returned when the versions container of a versioned container does not exist.
+var ErrVersionsContainer = &swift.Error{StatusCode: 412, Text: "Versions Container Not Found"}
+
+// VersionPrefix returns the prefix of the archived versions of the given object's key: `<len><name>/'.
+func VersionPrefix(key string) string {
+	return fmt.Sprintf("%03x%s/", len(key), key)
+}
+
+// VersionKey returns the key of the version of the given object's key archived at the given time.
+func VersionKey(key string, t time.Time) string {
+	return VersionPrefix(key) + fmt.Sprintf("%016.05f", float64(t.UnixNano())/1e9)
+}
+
+// A Versioning archives the previous versions of the objects of a container with legacy versioning enabled.
+//
+// https://docs.openstack.org/swift/latest/overview_object_versioning.html
+type Versioning struct {
+	database  database.Client
+	storage   storage.Backend
+	container *model.Container
+	versions  *model.Container
+	mode      string
+}
+
+// NewVersioning returns the Versioning of the container, nil when the versioning is disabled.
+func NewVersioning(database database.Client, storage storage.Backend, container *model.Container) (*Versioning, error) {
+	metas, err := database.FindMeta(container.ID, "")
+	if err != nil && !database.IsNotFound(err) {
+		return nil, errors.Wrap(err, "Versioning")
+	}
+
+	s := &Versioning{
+		database:  database,
+		storage:   storage,
+		container: container,
+	}
+
+	var location string
+	for _, meta := range metas {
+		if meta.Key == VersionsLocation || meta.Key == HistoryLocation {
+			s.mode, location = meta.Key, meta.Value
+		}
+	}
+	if location == "" {
+		return nil, nil
+	}
+
+	s.versions, err = database.FindContainerByName(container.ProjectID, location)
+	if err != nil {
+		if database.IsNotFound(err) {
+			return nil, ErrVersionsContainer
+		}
+		return nil, errors.Wrap(err, "Versioning")
+	}
+	return s, nil
+}
+
+// Archive copies the object into the versions container before its overwrite.
+func (s *Versioning) Archive(object *model.Object) error {
+	_, err := s.copy(s.container, object, s.versions, VersionKey(object.Key, *object.UpdatedAt))
+	return errors.Wrap(err, "Versioning archive")
+}
+
+// Delete removes the object of the given key according to the versioning mode, the object is nil when it does not exist.
+// It returns false when neither the object nor a previous version to restore has been found.
+func (s *Versioning) Delete(key string, object *model.Object) (bool, error) {
+	if s.mode == HistoryLocation {
+		if object == nil {
+			return false, nil
+		}
+		if err := s.Archive(object); err != nil {
+			return false, err
+		}
+		if err := s.mark(key); err != nil {
+			return false, errors.Wrap(err, "Versioning delete marker")
+		}
+		return true, NewObjectDestroyer(s.database, s.storage, s.container, object).Destroy()
+	}
+
+	versions, err := s.database.FindObjectsByContainerID(s.versions.ID, -1, VersionPrefix(key))
+	if err != nil && !s.database.IsNotFound(err) {
+		return false, errors.Wrap(err, "Versioning find versions")
+	}
+
+	if len(versions) == 0 {
+		if object == nil {
+			return false, nil
+		}
+		return true, NewObjectDestroyer(s.database, s.storage, s.container, object).Destroy()
+	}
+
+	// The most recent version replaces the object.
+	latest := versions[len(versions)-1]
+	if _, err = s.copy(s.versions, latest, s.container, key); err != nil {
+		return false, errors.Wrap(err, "Versioning restore")
+	}
+	return true, NewObjectDestroyer(s.database, s.storage, s.versions, latest).Destroy()
+}
+
+// mark archives an empty delete marker for the given key.
+func (s *Versioning) mark(key string) error {
+	marker := new(model.Object)
+	marker.ContainerID = s.versions.ID
+	marker.Key = VersionKey(key, time.Now())
+	marker.ContentType = DeleteMarker
+
+	uploader := NewObjectUploader(s.storage, s.versions, marker)
+	if err := uploader.Upload(bytes.NewReader(nil)); err != nil {
+		return err
+	}
+	return s.database.Save(marker)
+}
+
+// copy copies the object along with its metadata, the destination object is overwritten.
+func (s *Versioning) copy(src *model.Container, object *model.Object, dst *model.Container, key string) (*model.Object, error) {
+	if err := s.storage.Copy(src.Path(), object.Key, dst.Path(), key); err != nil {
+		return nil, err
+	}
+
+	target, err := s.database.FindObjectByKey(dst.ID, key)
+	if err != nil {
+		if !s.database.IsNotFound(err) {
+			return nil, err
+		}
+		target = new(model.Object)
+	}
+	target.ContainerID = dst.ID
+	target.Key = key
+	target.ContentType = object.ContentType
+	target.Checksum = object.Checksum
+	target.Size = object.Size
+
+	if err = s.database.Save(target); err != nil {
+		return nil, err
+	}
+
+	//
+
+	if err = copyMetas(s.database, src.ID, object.Key, dst.ID, key, CopyOptions{}); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
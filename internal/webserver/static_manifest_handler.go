@@ -0,0 +1,102 @@
+package webserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/webserver/serializer"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+	"github.com/ncw/swift/v2"
+)
+
+// StaticManifest creates a static large object from the JSON list of its segments (multipart-manifest=put).
+func (h *object) StaticManifest(c echo.Context) error {
+	c.Set("handler_method", "object.StaticManifest")
+
+	container, manifest, object, _, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	if container == nil {
+		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
+	}
+	if err = createOnly(c, manifest != nil || object != nil); err != nil {
+		return err
+	}
+
+	//
+
+	if manifest == nil {
+		manifest = new(model.Manifest)
+	}
+	manifest.ContainerID = container.ID
+	manifest.Key = c.Param("object")
+	manifest.ContentType = c.Request().Header.Get("Content-Type")
+
+	mc := service.NewStaticManifestCreation(h.db, h.storage, container, manifest, h.segmentAuthorizer(c))
+	err = mc.Create(c.Request().Body)
+	if errs, ok := err.(service.SegmentErrors); ok {
+		return weberror.New(http.StatusBadRequest, errs.Error())
+	}
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if etag := c.Request().Header.Get("Etag"); etag != "" && strings.Trim(etag, `"`) != manifest.Checksum {
+		return weberror.New(http.StatusUnprocessableEntity, swift.ObjectCorrupted.Text)
+	}
+
+	//
+
+	if err := h.db.Save(manifest); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if object != nil {
+		// The replaced object would hide the manifest.
+		if err = service.NewObjectDestroyer(h.db, h.storage, container, object).Destroy(); err != nil {
+			return weberror.New(http.StatusInternalServerError, err.Error())
+		}
+	}
+	if err = h.db.ReplaceMetas(container.ID, manifest.Key, objectMetadata(c.Request().Header)); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	//
+
+	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(manifest.CreatedAt.Unix(), 10))
+	c.Response().Header().Set("Etag", manifest.Checksum)
+	return c.NoContent(http.StatusCreated)
+}
+
+// segments renders the segments of a static large object (multipart-manifest=get).
+// The raw format returns them in the form used to upload the manifest.
+func (h *object) segments(c echo.Context, manifest *model.Manifest) error {
+	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(manifest.CreatedAt.Unix(), 10))
+	c.Response().Header().Set("Last-Modified", manifest.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.Response().Header().Set("X-Static-Large-Object", "True")
+
+	if c.QueryParam("format") == "raw" {
+		return c.JSON(http.StatusOK, serializer.RawSegments(manifest))
+	}
+	return c.JSON(http.StatusOK, serializer.Segments(manifest))
+}
+
+// deleteStaticManifest removes a static large object along with its segments (multipart-manifest=delete).
+func (h *object) deleteStaticManifest(c echo.Context, container *model.Container, manifest *model.Manifest) error {
+	deletion := new(service.Deletion)
+
+	err := service.NewStaticManifestDestroyer(h.db, h.storage, container, manifest, deletion).Destroy()
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	return renderDeletion(c, deletion)
+}
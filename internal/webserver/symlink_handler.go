@@ -0,0 +1,196 @@
+package webserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/openstackswift/internal/model"
+	middlewarepkg "github.com/mdouchement/openstackswift/internal/webserver/middleware"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+	"github.com/mdouchement/openstackswift/internal/xpath"
+	"github.com/ncw/swift/v2"
+)
+
+const (
+	// symloopMax is the maximum number of consecutive symlinks followed by a request.
+	symloopMax = 2
+	// symlinkContentType is the default content type of the symlinks.
+	symlinkContentType = "application/symlink"
+)
+
+// Symlink creates an object linking to the object of the X-Symlink-Target header, in another account with X-Symlink-Target-Account.
+// A static symlink, created with X-Symlink-Target-Etag, must link to an existing object with the given Etag.
+//
+// https://docs.openstack.org/swift/latest/middleware.html#symlink
+func (h *object) Symlink(c echo.Context) error {
+	c.Set("handler_method", "object.Symlink")
+
+	container, manifest, object, _, err := h.load(project(c).ID, c.Param("container"), c.Param("object"))
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+	if container == nil {
+		return weberror.New(http.StatusNotFound, swift.ContainerNotFound.Text)
+	}
+	if err = createOnly(c, manifest != nil || object != nil); err != nil {
+		return err
+	}
+
+	if body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1)); err != nil || len(body) > 0 {
+		return weberror.New(http.StatusBadRequest, "Symlink requests require a zero byte body")
+	}
+
+	header := c.Request().Header
+	target := header.Get("X-Symlink-Target")
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	if containername, key := xpath.Entities(target); containername == "" || key == "" || strings.HasPrefix(target, "/") {
+		return weberror.New(http.StatusPreconditionFailed, "X-Symlink-Target header must be of the form <container name>/<object name>")
+	}
+
+	account := project(c).Account()
+	if value := header.Get("X-Symlink-Target-Account"); value != "" {
+		if account, err = url.PathUnescape(value); err != nil || strings.Contains(account, "/") {
+			return weberror.New(http.StatusPreconditionFailed, "Account name cannot contain slashes")
+		}
+	}
+
+	link := &model.Object{
+		SymlinkTarget:  target,
+		SymlinkAccount: account,
+		SymlinkEtag:    strings.Trim(header.Get("X-Symlink-Target-Etag"), `"`),
+	}
+
+	// The target of a static symlink must exist.
+	if link.SymlinkEtag != "" {
+		if _, _, _, _, err = h.follow(c, link); err != nil {
+			return err
+		}
+	}
+
+	vid, err := archive(h.db, h.storage, container, object)
+	if err != nil {
+		return err
+	}
+
+	//
+
+	if object == nil {
+		object = new(model.Object)
+	}
+	object.ContainerID = container.ID
+	object.Key = c.Param("object")
+	object.VersionID = vid
+	object.SymlinkTarget = link.SymlinkTarget
+	object.SymlinkAccount = link.SymlinkAccount
+	object.SymlinkEtag = link.SymlinkEtag
+	object.ContentType = header.Get("Content-Type")
+	if object.ContentType == "" {
+		object.ContentType = symlinkContentType
+	}
+	if err = service.SetupObjectTTL(object, c.Request()); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	uploader := service.NewObjectUploader(h.storage, container, object)
+	if err = uploader.Upload(bytes.NewReader(nil)); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	if err = h.db.Save(object); err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	//
+
+	c.Response().Header().Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	c.Response().Header().Set("X-Timestamp", strconv.FormatInt(object.CreatedAt.Unix(), 10))
+	c.Response().Header().Set("Etag", object.Checksum)
+	c.Response().Header().Set("Location", object.SymlinkPath())
+	setVersionHeader(c, object, nil)
+	return c.NoContent(http.StatusCreated)
+}
+
+// follow resolves the target of the symlink, the chained symlinks are followed up to symloopMax.
+// The target of a static symlink must have the Etag of the link, otherwise 409 Conflict is returned.
+func (h *object) follow(c echo.Context, link *model.Object) (*model.Container, *model.Manifest, *model.Object, []*model.Meta, error) {
+	var container *model.Container
+	var manifest *model.Manifest
+	var metas []*model.Meta
+
+	object := link
+	for i := 0; object != nil && object.IsSymlink(); i++ {
+		if i >= symloopMax {
+			return nil, nil, nil, nil, weberror.New(http.StatusConflict, fmt.Sprintf("Too many levels of symbolic links, maximum allowed is %d", symloopMax))
+		}
+		link = object
+		container, manifest, object = nil, nil, nil
+
+		target, err := h.db.FindProject(strings.TrimPrefix(link.SymlinkAccount, "AUTH_"))
+		if err != nil && !h.db.IsNotFound(err) {
+			return nil, nil, nil, nil, weberror.New(http.StatusInternalServerError, err.Error())
+		}
+
+		containername, key := xpath.Entities(link.SymlinkTarget)
+		if err == nil {
+			allowed, err := middlewarepkg.Readable(h.db, c, target, containername)
+			if err != nil {
+				return nil, nil, nil, nil, weberror.New(http.StatusInternalServerError, err.Error())
+			}
+			if !allowed {
+				return nil, nil, nil, nil, weberror.New(http.StatusForbidden, swift.Forbidden.Text)
+			}
+
+			container, manifest, object, metas, err = h.load(target.ID, containername, key)
+			if err != nil {
+				return nil, nil, nil, nil, weberror.New(http.StatusInternalServerError, err.Error())
+			}
+		}
+
+		if container == nil || manifest == nil && object == nil {
+			if link.SymlinkEtag != "" {
+				return nil, nil, nil, nil, weberror.New(http.StatusConflict, "X-Symlink-Target does not exist")
+			}
+			return nil, nil, nil, nil, weberror.New(http.StatusNotFound, swift.ObjectNotFound.Text)
+		}
+
+		if link.SymlinkEtag != "" {
+			checksum := ""
+			switch {
+			case object != nil:
+				checksum = object.Checksum
+			case manifest.Static:
+				checksum = manifest.Checksum
+			}
+			if strings.Trim(checksum, `"`) != link.SymlinkEtag {
+				return nil, nil, nil, nil, weberror.New(http.StatusConflict, "Object Etag does not match X-Symlink-Target-Etag")
+			}
+		}
+
+		c.Response().Header().Set("Content-Location", link.SymlinkPath())
+	}
+
+	return container, manifest, object, metas, nil
+}
+
+// setSymlinkHeaders sets the target of the symlink requested with the `symlink=get' parameter.
+func setSymlinkHeaders(c echo.Context, object *model.Object) {
+	if object == nil || !object.IsSymlink() {
+		return
+	}
+
+	c.Response().Header().Set("X-Symlink-Target", object.SymlinkTarget)
+	c.Response().Header().Set("X-Symlink-Target-Account", object.SymlinkAccount)
+	if object.SymlinkEtag != "" {
+		c.Response().Header().Set("X-Symlink-Target-Etag", object.SymlinkEtag)
+	}
+}
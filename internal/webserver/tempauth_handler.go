@@ -0,0 +1,68 @@
+package webserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mdouchement/logger"
+	"github.com/mdouchement/openstackswift/internal/webserver/weberror"
+	"github.com/ncw/swift/v2"
+)
+
+// tempauth handles the legacy Swift v1.0 authentication.
+type tempauth struct {
+	identity
+	logger logger.Logger
+}
+
+// Authenticate authenticates `project:username' or `username' from the X-Auth-User and X-Auth-Key headers.
+func (h *tempauth) Authenticate(c echo.Context) error {
+	c.Set("handler_method", "tempauth.Authenticate")
+
+	// Filter params
+	username := c.Request().Header.Get("X-Auth-User")
+	if username == "" {
+		username = c.Request().Header.Get("X-Storage-User")
+	}
+	password := c.Request().Header.Get("X-Auth-Key")
+	if password == "" {
+		password = c.Request().Header.Get("X-Storage-Pass")
+	}
+	if username == "" || password == "" {
+		return weberror.New(http.StatusUnauthorized, swift.AuthorizationFailed.Text)
+	}
+
+	var projectname string
+	if i := strings.LastIndex(username, ":"); i >= 0 {
+		projectname, username = username[:i], username[i+1:]
+	}
+
+	// Authorization
+	user, err := h.authenticate(username, password, "", projectname)
+	if err != nil {
+		return weberror.New(http.StatusBadRequest, swift.BadRequest.Text)
+	}
+	if user == nil {
+		return weberror.New(http.StatusUnauthorized, swift.AuthorizationFailed.Text)
+	}
+
+	token, err := h.issue(user, nil, "password")
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	project, err := h.db.FindProject(token.ProjectID)
+	if err != nil {
+		return weberror.New(http.StatusInternalServerError, err.Error())
+	}
+
+	// Render response
+	c.Response().Header().Set("X-Storage-Url", h.storageURL(c, project))
+	c.Response().Header().Set("X-Auth-Token", token.Key)
+	c.Response().Header().Set("X-Storage-Token", token.Key)
+	c.Response().Header().Set("X-Auth-Token-Expires", strconv.FormatInt(int64(time.Until(token.ExpiresAt).Seconds()), 10))
+	return c.NoContent(http.StatusOK)
+}
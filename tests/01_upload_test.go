@@ -1,11 +1,15 @@
 package tests
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/md5"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -85,3 +89,109 @@ func TestUploadLargeObject(t *testing.T) {
 	assert.Equal(t, "text/plain; charset=utf-8", info.ContentType)
 	assert.NotEmpty(t, info.Hash)
 }
+
+func TestUploadCreateOnly(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	createOnly := swift.Headers{"If-None-Match": "*"}
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader("first"), false, "", "text/plain", createOnly)
+	assert.NoError(t, err)
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader("second"), false, "", "text/plain", createOnly)
+	if assert.Error(t, err) {
+		assert.Equal(t, 412, err.(*swift.Error).StatusCode)
+	}
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader("second"), false, "", "text/plain", swift.Headers{"If-None-Match": "etag"})
+	if assert.Error(t, err) {
+		assert.Equal(t, 400, err.(*swift.Error).StatusCode)
+	}
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", payload)
+
+	//
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1/b2/c3.txt", "Xcontainer", "d4.txt", createOnly)
+	assert.NoError(t, err)
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1/b2/c3.txt", "Xcontainer", "d4.txt", createOnly)
+	if assert.Error(t, err) {
+		assert.Equal(t, 412, err.(*swift.Error).StatusCode)
+	}
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "d4.txt", bytes.NewReader(nil), false, "", "text/plain", swift.Headers{
+		"If-None-Match":     "*",
+		"X-Object-Manifest": "Xcontainer/a1",
+	})
+	if assert.Error(t, err) {
+		assert.Equal(t, 412, err.(*swift.Error).StatusCode)
+	}
+}
+
+func TestUploadVerification(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	content := "0123456789"
+	checksum := fmt.Sprintf("%x", md5.Sum([]byte(content)))
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "valid.txt", strings.NewReader(content), true, checksum, "text/plain", nil)
+	assert.NoError(t, err)
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "corrupted.txt", strings.NewReader(content), true, "0123456789abcdef0123456789abcdef", "text/plain", nil)
+	assert.Equal(t, swift.ObjectCorrupted, err)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "corrupted.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	// A failed upload keeps the previous version.
+	_, err = c.ObjectPut(ctx, "Xcontainer", "valid.txt", strings.NewReader("overwritten"), true, "0123456789abcdef0123456789abcdef", "text/plain", nil)
+	assert.Equal(t, swift.ObjectCorrupted, err)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "valid.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, content, payload)
+
+	//
+
+	u, err := url.Parse(c.StorageUrl)
+	assert.NoError(t, err)
+
+	conn, err := net.Dial("tcp", u.Host)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "PUT %s/Xcontainer/short.txt HTTP/1.1\r\nHost: %s\r\nX-Auth-Token: %s\r\nContent-Length: 10\r\n\r\n01234", u.Path, u.Host, c.AuthToken)
+	conn.(*net.TCPConn).CloseWrite()
+
+	res, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if assert.NoError(t, err) {
+		res.Body.Close()
+		assert.Equal(t, 499, res.StatusCode)
+	}
+
+	_, _, err = c.Object(ctx, "Xcontainer", "short.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+}
@@ -3,7 +3,12 @@ package tests
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
@@ -84,3 +89,225 @@ func TestDownloadLargeObject(t *testing.T) {
 
 	assert.Equal(t, content, string(payload))
 }
+
+func TestDownloadDynamicLargeObject(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Chunks-Container", swift.Headers{})
+	assert.NoError(t, err)
+
+	// The segments are resolved at read time, they can be uploaded after the manifest.
+	_, err = c.ObjectPut(ctx, "Xcontainer", "digits.txt", bytes.NewReader(nil), false, "", "text/plain", swift.Headers{
+		"X-Object-Manifest": "Chunks-Container/segments/file_",
+	})
+	assert.NoError(t, err)
+
+	info, headers, err := c.Object(ctx, "Xcontainer", "digits.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), info.Bytes)
+	assert.Equal(t, "Chunks-Container/segments/file_", headers["X-Object-Manifest"])
+
+	checksums := ""
+	for i, segment := range []string{"0123", "4567", "89"} {
+		err = c.ObjectPutString(ctx, "Chunks-Container", fmt.Sprintf("segments/file_%08d", i), segment, "text/plain")
+		assert.NoError(t, err)
+		checksums += fmt.Sprintf("%x", md5.Sum([]byte(segment)))
+	}
+	err = c.ObjectPutString(ctx, "Chunks-Container", "segments/other", "ignored", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	info, _, err = c.Object(ctx, "Xcontainer", "digits.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), info.Bytes)
+
+	res := rangeRequest(t, c, "digits.txt", "")
+	res.Body.Close()
+	assert.Equal(t, fmt.Sprintf(`"%x"`, md5.Sum([]byte(checksums))), res.Header.Get("Etag"))
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "digits.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", payload)
+
+	err = c.ObjectPutString(ctx, "Chunks-Container", "segments/file_00000003", "ab", "text/plain")
+	assert.NoError(t, err)
+
+	payload, err = c.ObjectGetString(ctx, "Xcontainer", "digits.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789ab", payload)
+}
+
+func TestDownloadRange(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "0123456789", "text/plain")
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Chunks-Container", swift.Headers{})
+	assert.NoError(t, err)
+	for i, segment := range []string{"0123", "4567", "89"} {
+		err = c.ObjectPutString(ctx, "Chunks-Container", fmt.Sprintf("a42/%08d", i), segment, "text/plain")
+		assert.NoError(t, err)
+	}
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a42/digits.txt", bytes.NewReader(nil), false, "", "text/plain", swift.Headers{
+		"X-Object-Manifest": "Chunks-Container/a42",
+	})
+	assert.NoError(t, err)
+
+	//
+
+	for _, object := range []string{"a1/b2/c3.txt", "a42/digits.txt"} {
+		for _, tc := range []struct {
+			header, payload, contentRange string
+			status                        int
+		}{
+			{"bytes=2-5", "2345", "bytes 2-5/10", http.StatusPartialContent},
+			{"bytes=7-", "789", "bytes 7-9/10", http.StatusPartialContent},
+			{"bytes=-3", "789", "bytes 7-9/10", http.StatusPartialContent},
+			{"bytes=3-100", "3456789", "bytes 3-9/10", http.StatusPartialContent},
+			{"bytes=20-30", "", "bytes */10", http.StatusRequestedRangeNotSatisfiable},
+			{"bytes=5-2", "0123456789", "", http.StatusOK},
+		} {
+			_, headers, err := c.Object(ctx, "Xcontainer", object)
+			assert.NoError(t, err)
+			assert.Equal(t, "bytes", headers["Accept-Ranges"])
+
+			res := rangeRequest(t, c, object, tc.header)
+			payload, err := io.ReadAll(res.Body)
+			res.Body.Close()
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.status, res.StatusCode, object+" "+tc.header)
+			assert.Equal(t, tc.contentRange, res.Header.Get("Content-Range"), object+" "+tc.header)
+			if tc.status != http.StatusRequestedRangeNotSatisfiable {
+				assert.Equal(t, tc.payload, string(payload), object+" "+tc.header)
+			}
+		}
+
+		// Multiple ranges
+		res := rangeRequest(t, c, object, "bytes=0-1,-2")
+		assert.Equal(t, http.StatusPartialContent, res.StatusCode)
+
+		mediatype, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/byteranges", mediatype)
+
+		mr := multipart.NewReader(res.Body, params["boundary"])
+		for _, expected := range []struct{ payload, contentRange string }{{"01", "bytes 0-1/10"}, {"89", "bytes 8-9/10"}} {
+			part, err := mr.NextPart()
+			if !assert.NoError(t, err) {
+				break
+			}
+			payload, err := io.ReadAll(part)
+			assert.NoError(t, err)
+			assert.Equal(t, expected.payload, string(payload))
+			assert.Equal(t, expected.contentRange, part.Header.Get("Content-Range"))
+		}
+		_, err = mr.NextPart()
+		assert.Equal(t, io.EOF, err)
+		res.Body.Close()
+	}
+}
+
+func rangeRequest(t *testing.T, c *swift.Connection, object, header string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, c.StorageUrl+"/Xcontainer/"+object, nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	req.Header.Set("Range", header)
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestDownloadConditional(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "0123456789", "text/plain")
+	assert.NoError(t, err)
+
+	_, headers, err := c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	etag := headers["Etag"]
+	modified, err := http.ParseTime(headers["Last-Modified"])
+	assert.NoError(t, err)
+
+	//
+
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		for _, tc := range []struct {
+			header, value string
+			status        int
+		}{
+			{"If-Match", `"` + etag + `"`, http.StatusOK},
+			{"If-Match", "nope, " + etag, http.StatusOK},
+			{"If-Match", "nope", http.StatusPreconditionFailed},
+			{"If-None-Match", etag, http.StatusNotModified},
+			{"If-None-Match", `W/"` + etag + `"`, http.StatusNotModified},
+			{"If-None-Match", "*", http.StatusNotModified},
+			{"If-None-Match", "nope", http.StatusOK},
+			{"If-Modified-Since", after, http.StatusNotModified},
+			{"If-Modified-Since", before, http.StatusOK},
+			{"If-Unmodified-Since", before, http.StatusPreconditionFailed},
+			{"If-Unmodified-Since", after, http.StatusOK},
+		} {
+			req, err := http.NewRequest(method, c.StorageUrl+"/Xcontainer/a1/b2/c3.txt", nil)
+			assert.NoError(t, err)
+			req.Header.Set("X-Auth-Token", c.AuthToken)
+			req.Header.Set(tc.header, tc.value)
+
+			res, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			res.Body.Close()
+			assert.Equal(t, tc.status, res.StatusCode, method+" "+tc.header+": "+tc.value)
+			if tc.status == http.StatusNotModified {
+				assert.Equal(t, etag, res.Header.Get("Etag"))
+			}
+		}
+	}
+
+	// If-Range
+	for value, status := range map[string]int{etag: http.StatusPartialContent, "nope": http.StatusOK, before: http.StatusOK} {
+		req, err := http.NewRequest(http.MethodGet, c.StorageUrl+"/Xcontainer/a1/b2/c3.txt", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+		req.Header.Set("Range", "bytes=0-1")
+		req.Header.Set("If-Range", value)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, status, res.StatusCode, "If-Range: "+value)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"net/http"
+	"net/url"
+	"path"
 	"strings"
 	"testing"
 	"time"
@@ -94,3 +97,179 @@ func TestCopyLargeObject(t *testing.T) {
 
 	assert.Equal(t, content, string(payload))
 }
+
+func TestCopyObjectMetadata(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	headers := swift.Metadata{"color": "orange", "size": "big"}.ObjectHeaders()
+	headers["Content-Disposition"] = "inline"
+	headers["X-Delete-After"] = "3600"
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1.txt", strings.NewReader("content"), false, "", "text/plain", headers)
+	assert.NoError(t, err)
+
+	//
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Xcontainer", "a2.txt", swift.Headers{
+		"Content-Type":               "text/markdown",
+		"X-Object-Meta-Size":         "small",
+		"X-Remove-Object-Meta-Color": "x",
+		"X-Object-Meta-Shape":        "round",
+	})
+	assert.NoError(t, err)
+
+	info, headers, err := c.Object(ctx, "Xcontainer", "a2.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "text/markdown", info.ContentType)
+	assert.Equal(t, swift.Metadata{"size": "small", "shape": "round"}, headers.ObjectMetadata())
+	assert.Equal(t, "inline", headers["Content-Disposition"])
+	assert.NotEmpty(t, headers["X-Delete-At"])
+
+	//
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Xcontainer", "a2.txt", swift.Headers{
+		"X-Fresh-Metadata":    "true",
+		"X-Object-Meta-Shape": "square",
+	})
+	assert.NoError(t, err)
+
+	info, headers, err = c.Object(ctx, "Xcontainer", "a2.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", info.ContentType)
+	assert.Equal(t, swift.Metadata{"shape": "square"}, headers.ObjectMetadata())
+	assert.NotContains(t, headers, "Content-Disposition")
+	assert.NotContains(t, headers, "X-Delete-At")
+
+	objects, err := c.ObjectNames(ctx, "Xcontainer", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1.txt", "a2.txt"}, objects)
+}
+
+func TestCopyObjectErrors(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1.txt", "content", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Missing", "a2.txt", nil)
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "missing.txt", "Xcontainer", "a2.txt", nil)
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Xcontainer", "", nil)
+	if assert.IsType(t, &swift.Error{}, err) {
+		assert.Equal(t, http.StatusPreconditionFailed, err.(*swift.Error).StatusCode)
+	}
+}
+
+func TestCopyObjectToAccount(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+	account := path.Base(other.StorageUrl)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1.txt", "content", "text/plain")
+	assert.NoError(t, err)
+	err = other.ContainerCreate(ctx, "Ycontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	//
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Ycontainer", "a2.txt", swift.Headers{"Destination-Account": account})
+	assert.Equal(t, swift.Forbidden, err)
+
+	err = other.ContainerUpdate(ctx, "Ycontainer", swift.Headers{"X-Container-Write": "test:tester"})
+	assert.NoError(t, err)
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Ycontainer", "a2.txt", swift.Headers{"Destination-Account": account})
+	assert.NoError(t, err)
+
+	payload, err := other.ObjectGetString(ctx, "Ycontainer", "a2.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "content", payload)
+}
+
+func TestCopyStaticLargeObjectManifest(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Chunks-Container", swift.Headers{})
+	assert.NoError(t, err)
+
+	content := strings.Repeat(time.Now().Format(time.RFC3339), 100<<10) // 2.5MiB
+
+	slo, err := c.StaticLargeObjectCreate(ctx, &swift.LargeObjectOpts{
+		Container:        "Xcontainer",
+		ObjectName:       "a42/dates.txt",
+		ContentType:      "text/plain",
+		SegmentContainer: "Chunks-Container",
+		SegmentPrefix:    "a42",
+		ChunkSize:        1 << 20, // 1 MiB
+	})
+	assert.NoError(t, err)
+	_, err = io.Copy(slo, bytes.NewBufferString(content))
+	assert.NoError(t, err)
+	err = slo.Close()
+	assert.NoError(t, err)
+
+	//
+
+	_, _, err = c.Call(ctx, c.StorageUrl, swift.RequestOpts{
+		Container:  "Xcontainer",
+		ObjectName: "a42/dates.txt",
+		Operation:  "COPY",
+		Parameters: url.Values{"multipart-manifest": {"get"}},
+		Headers:    swift.Headers{"Destination": "Xcontainer/a43/dates.txt"},
+		NoResponse: true,
+	})
+	assert.NoError(t, err)
+
+	info, headers, err := c.Object(ctx, "Xcontainer", "a43/dates.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "True", headers["X-Static-Large-Object"])
+	assert.Equal(t, int64(len(content)), info.Bytes)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "a43/dates.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, content, payload)
+}
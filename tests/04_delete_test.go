@@ -77,6 +77,10 @@ func TestDeleteLargeObject(t *testing.T) {
 	err = dlo.Close()
 	assert.NoError(t, err)
 
+	segments, err := c.ObjectNames(ctx, "Chunks-Container", nil)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, segments)
+
 	//
 
 	err = c.ObjectDelete(ctx, "Xcontainer", "a42/dates.txt")
@@ -87,9 +91,38 @@ func TestDeleteLargeObject(t *testing.T) {
 	_, _, err = c.Object(ctx, "Xcontainer", "a42/dates.txt")
 	assert.Error(t, swift.ObjectNotFound, err)
 
+	// Only the manifest is deleted, the segments are kept.
+	names, err := c.ObjectNames(ctx, "Chunks-Container", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, segments, names)
+}
+
+func TestDeleteDynamicManifestKeepsPrefix(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "seg", swift.Headers{})
+	assert.NoError(t, err)
+	for _, name := range []string{"p/1", "p/2", "p/keep"} {
+		err = c.ObjectPutString(ctx, "seg", name, name, "text/plain")
+		assert.NoError(t, err)
+	}
+
+	_, err = c.ObjectPut(ctx, "seg", "manifest", strings.NewReader(""), false, "", "text/plain", swift.Headers{"X-Object-Manifest": "seg/p/"})
+	assert.NoError(t, err)
+
 	//
 
-	objects, err := c.Objects(ctx, "Chunks-Container", &swift.ObjectsOpts{})
+	err = c.ObjectDelete(ctx, "seg", "manifest")
+	assert.NoError(t, err)
+
+	names, err := c.ObjectNames(ctx, "seg", nil)
 	assert.NoError(t, err)
-	assert.Equal(t, 0, len(objects))
+	assert.Equal(t, []string{"p/1", "p/2", "p/keep"}, names)
 }
@@ -42,6 +42,42 @@ func TestUpdateMetaContainer(t *testing.T) {
 
 }
 
+func TestCreateMetaContainer(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	// The metadata and the ACLs are set by the creation.
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{
+		"X-Container-Meta-Color": "orange",
+		"X-Container-Read":       ".r:*",
+	})
+	assert.NoError(t, err)
+
+	_, headers, err := c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{"color": "orange"}, headers.ContainerMetadata())
+	assert.Equal(t, ".r:*", headers["X-Container-Read"])
+
+	// They are updated by the creation of an existing container.
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{
+		"X-Container-Meta-Size": "42",
+		"X-Container-Write":     "other:*",
+	})
+	assert.NoError(t, err)
+
+	_, headers, err = c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{"color": "orange", "size": "42"}, headers.ContainerMetadata())
+	assert.Equal(t, ".r:*", headers["X-Container-Read"])
+	assert.Equal(t, "other:*", headers["X-Container-Write"])
+}
+
 func TestUpdateMetaObject(t *testing.T) {
 	c, cleanup := setup()
 	defer cleanup()
@@ -147,3 +183,202 @@ func TestUpdateMetaLargeObject(t *testing.T) {
 	assert.NotEmpty(t, info.Hash)
 	assert.Equal(t, m, headers.ObjectMetadata())
 }
+
+func TestReplaceMetaObject(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1.txt", "content", "text/plain")
+	assert.NoError(t, err)
+
+	m := swift.Metadata{"color": "orange", "size": "big"}
+	err = c.ObjectUpdate(ctx, "Xcontainer", "a1.txt", m.ObjectHeaders())
+	assert.NoError(t, err)
+
+	//
+
+	headers := swift.Metadata{"color": "blue"}.ObjectHeaders()
+	headers["X-Object-Meta-Empty"] = ""
+	headers["X-Unrelated-Header"] = "ignored"
+	err = c.ObjectUpdate(ctx, "Xcontainer", "a1.txt", headers)
+	assert.NoError(t, err)
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a1.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{"color": "blue"}, headers.ObjectMetadata())
+	assert.NotContains(t, headers, "X-Unrelated-Header")
+
+	//
+
+	err = c.ObjectUpdate(ctx, "Xcontainer", "a1.txt", swift.Headers{})
+	assert.NoError(t, err)
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a1.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{}, headers.ObjectMetadata())
+}
+
+func TestRemoveMetaContainer(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	m := swift.Metadata{"color": "orange", "size": "big", "shape": "round"}
+	err = c.ContainerUpdate(ctx, "Xcontainer", m.ContainerHeaders())
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{
+		"X-Container-Meta-Color":        "blue",
+		"X-Remove-Container-Meta-Size":  "x",
+		"X-Container-Meta-Shape":        "",
+		"X-Container-Meta-Owner":        "me",
+		"X-Remove-Container-Meta-Owner": "x",
+	})
+	assert.NoError(t, err)
+
+	_, headers, err := c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{"color": "blue"}, headers.ContainerMetadata())
+
+	//
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Metadata{"size": "small"}.ContainerHeaders())
+	assert.NoError(t, err)
+
+	_, headers, err = c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{"color": "blue", "size": "small"}, headers.ContainerMetadata())
+}
+
+func TestDeleteMetaObject(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1.txt", "content", "text/plain")
+	assert.NoError(t, err)
+	err = c.ObjectUpdate(ctx, "Xcontainer", "a1.txt", swift.Metadata{"color": "orange"}.ObjectHeaders())
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1.txt")
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1.txt", "content", "text/plain")
+	assert.NoError(t, err)
+
+	_, headers, err := c.Object(ctx, "Xcontainer", "a1.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{}, headers.ObjectMetadata())
+}
+
+func TestUploadMetaObject(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	m := swift.Metadata{"color": "orange"}
+	headers := m.ObjectHeaders()
+	headers["Content-Disposition"] = `attachment; filename="c3.txt"`
+	headers["Content-Language"] = "en"
+	headers["X-Unrelated-Header"] = "ignored"
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader("content"), false, "", "text/plain", headers)
+	assert.NoError(t, err)
+
+	//
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, m, headers.ObjectMetadata())
+	assert.Equal(t, `attachment; filename="c3.txt"`, headers["Content-Disposition"])
+	assert.Equal(t, "en", headers["Content-Language"])
+	assert.NotContains(t, headers, "X-Unrelated-Header")
+
+	_, headers, err = c.ObjectOpen(ctx, "Xcontainer", "a1/b2/c3.txt", false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, headers.ObjectMetadata())
+	assert.Equal(t, `attachment; filename="c3.txt"`, headers["Content-Disposition"])
+
+	//
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader("content"), false, "", "text/plain", swift.Headers{})
+	assert.NoError(t, err)
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{}, headers.ObjectMetadata())
+	assert.NotContains(t, headers, "Content-Disposition")
+}
+
+func TestCopyMetaObject(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	m := swift.Metadata{"color": "orange"}
+	headers := m.ObjectHeaders()
+	headers["Content-Disposition"] = "inline"
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1.txt", strings.NewReader("content"), false, "", "text/plain", headers)
+	assert.NoError(t, err)
+
+	//
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Xcontainer", "a2.txt", swift.Headers{})
+	assert.NoError(t, err)
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a2.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, m, headers.ObjectMetadata())
+	assert.Equal(t, "inline", headers["Content-Disposition"])
+
+	//
+
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a1.txt", "Xcontainer", "a3.txt", swift.Headers{"X-Fresh-Metadata": "true"})
+	assert.NoError(t, err)
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{}, headers.ObjectMetadata())
+	assert.NotContains(t, headers, "Content-Disposition")
+}
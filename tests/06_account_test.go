@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/asdine/storm/v3"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/mdouchement/openstackswift/internal/webserver/service"
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountIsolation(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.NotEqual(t, c.StorageUrl, other.StorageUrl)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "tester", "text/plain")
+	assert.NoError(t, err)
+
+	err = other.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = other.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "visitor", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "tester", payload)
+
+	payload, err = other.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "visitor", payload)
+
+	//
+
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	err = c.ContainerDelete(ctx, "Xcontainer")
+	assert.NoError(t, err)
+
+	containers, err := c.Containers(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, containers)
+
+	containers, err = other.Containers(ctx, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, containers, 1) {
+		assert.Equal(t, "Xcontainer", containers[0].Name)
+	}
+}
+
+func TestAccountForbidden(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	//
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+	other.StorageUrl = c.StorageUrl
+
+	_, _, err = other.Container(ctx, "Xcontainer")
+	assert.Equal(t, swift.Forbidden, err)
+}
+
+func TestAccountBadCredentials(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	c.ApiKey = "wrong"
+	err := c.Authenticate(context.Background())
+	assert.Equal(t, swift.AuthorizationFailed, err)
+}
+
+func TestAccountMetadata(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Ycontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "tester", "text/plain")
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Ycontainer", "d4.txt", "test", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	info, headers, err := c.Account(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), info.Containers)
+	assert.Equal(t, int64(2), info.Objects)
+	assert.Equal(t, int64(10), info.BytesUsed)
+	assert.Empty(t, headers.AccountMetadata())
+
+	//
+
+	err = c.AccountUpdate(ctx, swift.Metadata{"color": "blue", "shape": "round"}.AccountHeaders())
+	assert.NoError(t, err)
+	err = c.AccountUpdate(ctx, swift.Metadata{"color": "red"}.AccountHeaders())
+	assert.NoError(t, err)
+
+	_, headers, err = c.Account(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{"color": "red", "shape": "round"}, headers.AccountMetadata())
+
+	err = c.AccountUpdate(ctx, swift.Headers{"X-Remove-Account-Meta-Shape": "x"})
+	assert.NoError(t, err)
+
+	_, headers, err = c.Account(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, swift.Metadata{"color": "red"}, headers.AccountMetadata())
+
+	//
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	other.StorageUrl = c.StorageUrl
+	_, _, err = other.Account(ctx)
+	assert.Error(t, err)
+	err = other.AccountUpdate(ctx, swift.Metadata{"color": "green"}.AccountHeaders())
+	assert.Equal(t, swift.Forbidden, err)
+}
+
+func TestAccountAdoptContainers(t *testing.T) {
+	dbname, err := os.CreateTemp(os.TempDir(), "swift.db.")
+	assert.NoError(t, err)
+	dbname.Close()
+	defer os.RemoveAll(dbname.Name())
+
+	workspace, err := os.MkdirTemp(os.TempDir(), "swift.")
+	assert.NoError(t, err)
+	defer os.RemoveAll(workspace)
+	backend := storage.NewFileSystem(workspace)
+
+	// A database written before the accounts, its containers have no project.
+	type Container struct {
+		ID    string `json:"uuid"  storm:"id"`
+		Name  string `json:"name"  storm:"unique"`
+		Count int    `json:"count"`
+		Bytes int64  `json:"bytes"`
+	}
+
+	db, err := storm.Open(dbname.Name(), database.StormCodec)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Save(&Container{ID: "c1", Name: "Xcontainer", Count: 1, Bytes: 10}))
+	object := &model.Object{ContainerID: "c1", Key: "a1.txt", Size: 10}
+	object.ID = "o1"
+	assert.NoError(t, db.Save(object))
+	assert.NoError(t, db.Close())
+
+	wc, err := backend.Writer("Xcontainer", "a1.txt")
+	assert.NoError(t, err)
+	_, err = wc.Write([]byte("0123456789"))
+	assert.NoError(t, err)
+	assert.NoError(t, wc.Commit())
+
+	//
+
+	assert.NoError(t, database.StormInit(dbname.Name()))
+	assert.NoError(t, database.StormReIndex(dbname.Name()))
+
+	client, err := database.StormOpen(dbname.Name())
+	assert.NoError(t, err)
+	defer client.Close()
+
+	user, err := database.RegisterUser(client, "Default", "test", "tester", "testing")
+	assert.NoError(t, err)
+	assert.NoError(t, service.AdoptContainers(client, backend, user.ProjectID))
+
+	container, err := client.FindContainerByName(user.ProjectID, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, "c1", container.ID)
+
+	object, err = client.FindObjectByKey(container.ID, "a1.txt")
+	assert.NoError(t, err)
+
+	rc, err := service.NewObjectDownloader(backend, container, object).Stream()
+	assert.NoError(t, err)
+	defer rc.Close()
+	payload, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", string(payload))
+
+	// The adoption is done once.
+	containers, err := client.ListContainers("")
+	assert.NoError(t, err)
+	assert.Empty(t, containers)
+	assert.NoError(t, service.AdoptContainers(client, backend, user.ProjectID))
+}
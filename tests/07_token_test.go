@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenIssuance(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+	token := c.AuthToken
+
+	assert.NotEmpty(t, token)
+	if authVersion != 1 { // TempAuth's expiration is not read by the client
+		assert.WithinDuration(t, time.Now().Add(time.Hour), c.Expires, time.Minute)
+	}
+
+	//
+
+	c.UnAuthenticate()
+	err = c.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, c.AuthToken)
+}
+
+func TestTokenInvalid(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	//
+
+	// The client re-authenticates and retries when it gets a 401.
+	c.AuthToken = "tk_tester"
+	_, _, err = c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "tk_tester", c.AuthToken)
+}
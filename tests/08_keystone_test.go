@@ -0,0 +1,481 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeystoneValidateToken(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	req, err := http.NewRequest(http.MethodGet, c.AuthUrl+"/auth/tokens", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	req.Header.Set("X-Subject-Token", c.AuthToken)
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, c.AuthToken, res.Header.Get("X-Subject-Token"))
+
+	var payload struct {
+		Token struct {
+			Methods []string `json:"methods"`
+			User    struct {
+				Name string `json:"name"`
+			} `json:"user"`
+			Project struct {
+				Name string `json:"name"`
+			} `json:"project"`
+			Catalog []struct {
+				Type      string `json:"type"`
+				Endpoints []struct {
+					URL string `json:"url"`
+				} `json:"endpoints"`
+			} `json:"catalog"`
+		} `json:"token"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&payload)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"password"}, payload.Token.Methods)
+	assert.Equal(t, "tester", payload.Token.User.Name)
+	assert.Equal(t, "test", payload.Token.Project.Name)
+	if assert.Len(t, payload.Token.Catalog, 1) {
+		assert.Equal(t, "object-store", payload.Token.Catalog[0].Type)
+		assert.Equal(t, c.StorageUrl, payload.Token.Catalog[0].Endpoints[0].URL)
+	}
+
+	//
+
+	req.Header.Set("X-Subject-Token", "unknown")
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	req.Header.Set("X-Auth-Token", "unknown")
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestKeystoneRevokeToken(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	req, err := http.NewRequest(http.MethodDelete, c.AuthUrl+"/auth/tokens", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	req.Header.Set("X-Subject-Token", c.AuthToken)
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	//
+
+	req, err = http.NewRequest(http.MethodHead, c.StorageUrl+"/Xcontainer", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestKeystoneOtherUserToken(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	// A service validates the user's token with its own token but can not revoke it.
+	for method, status := range map[string]int{
+		http.MethodGet:    http.StatusOK,
+		http.MethodHead:   http.StatusOK,
+		http.MethodDelete: http.StatusForbidden,
+	} {
+		req, err := http.NewRequest(method, c.AuthUrl+"/auth/tokens", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", other.AuthToken)
+		req.Header.Set("X-Subject-Token", c.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, status, res.StatusCode, method)
+		if status == http.StatusOK {
+			assert.Equal(t, c.AuthToken, res.Header.Get("X-Subject-Token"), method)
+		}
+	}
+
+	_, _, err = c.Account(ctx)
+	assert.NoError(t, err)
+}
+
+func TestKeystoneCatalog(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	req, err := http.NewRequest(http.MethodGet, c.AuthUrl+"/auth/catalog", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var payload struct {
+		Catalog []struct {
+			Type string `json:"type"`
+		} `json:"catalog"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&payload)
+	assert.NoError(t, err)
+	if assert.Len(t, payload.Catalog, 1) {
+		assert.Equal(t, "object-store", payload.Catalog[0].Type)
+	}
+
+	//
+
+	res, err = http.Get(c.AuthUrl)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestKeystoneTokenMethod(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	rescoped := &swift.Connection{
+		AuthUrl: c.AuthUrl,
+		Token:   c.AuthToken,
+		Tenant:  c.Tenant,
+		Domain:  c.Domain,
+		Region:  c.Region,
+	}
+	err = rescoped.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, c.StorageUrl, rescoped.StorageUrl)
+	assert.NotEqual(t, c.AuthToken, rescoped.AuthToken)
+
+	// The rescoped token does not outlive the token it was issued from.
+	expiration := func(token string) string {
+		req, err := http.NewRequest(http.MethodGet, c.AuthUrl+"/auth/tokens?nocatalog", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", token)
+		req.Header.Set("X-Subject-Token", token)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		var payload struct {
+			Token struct {
+				ExpiresAt string `json:"expires_at"`
+			} `json:"token"`
+		}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&payload))
+		return payload.Token.ExpiresAt
+	}
+	time.Sleep(time.Second)
+	chained := &swift.Connection{
+		AuthUrl: c.AuthUrl,
+		Token:   rescoped.AuthToken,
+		Tenant:  c.Tenant,
+		Domain:  c.Domain,
+		Region:  c.Region,
+	}
+	err = chained.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, expiration(c.AuthToken))
+	assert.Equal(t, expiration(c.AuthToken), expiration(chained.AuthToken))
+
+	//
+
+	rescoped = &swift.Connection{
+		AuthUrl: c.AuthUrl,
+		Token:   c.AuthToken,
+		Tenant:  "other",
+		Domain:  c.Domain,
+		Region:  c.Region,
+	}
+	err = rescoped.Authenticate(ctx)
+	assert.Equal(t, swift.AuthorizationFailed, err)
+}
+
+func TestKeystoneProjectID(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	byid := &swift.Connection{
+		AuthUrl:  c.AuthUrl,
+		TenantId: strings.TrimPrefix(path.Base(c.StorageUrl), "AUTH_"),
+		Domain:   c.Domain,
+		UserName: c.UserName,
+		ApiKey:   c.ApiKey,
+		Region:   c.Region,
+	}
+	err = byid.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, c.StorageUrl, byid.StorageUrl)
+}
+
+func TestKeystoneApplicationCredential(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	req, err := http.NewRequest(http.MethodGet, c.AuthUrl+"/auth/tokens?nocatalog", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	req.Header.Set("X-Subject-Token", c.AuthToken)
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	var token struct {
+		Token struct {
+			User struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"token"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&token)
+	assert.NoError(t, err)
+
+	//
+
+	body := strings.NewReader(`{"application_credential": {"name": "ci"}}`)
+	req, err = http.NewRequest(http.MethodPost, c.AuthUrl+"/users/"+token.Token.User.ID+"/application_credentials", body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var credential struct {
+		ApplicationCredential struct {
+			ID     string `json:"id"`
+			Secret string `json:"secret"`
+		} `json:"application_credential"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&credential)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, credential.ApplicationCredential.Secret)
+
+	//
+
+	byid := &swift.Connection{
+		AuthUrl:                     c.AuthUrl,
+		ApplicationCredentialId:     credential.ApplicationCredential.ID,
+		ApplicationCredentialSecret: credential.ApplicationCredential.Secret,
+		Region:                      c.Region,
+	}
+	err = byid.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, c.StorageUrl, byid.StorageUrl)
+
+	byname := &swift.Connection{
+		AuthUrl:                     c.AuthUrl,
+		ApplicationCredentialName:   "ci",
+		ApplicationCredentialSecret: credential.ApplicationCredential.Secret,
+		UserName:                    c.UserName,
+		Domain:                      c.Domain,
+		Region:                      c.Region,
+	}
+	err = byname.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, c.StorageUrl, byname.StorageUrl)
+
+	byname.UnAuthenticate()
+	byname.ApplicationCredentialSecret = "wrong"
+	err = byname.Authenticate(ctx)
+	assert.Equal(t, swift.AuthorizationFailed, err)
+}
+
+func TestKeystoneApplicationCredentialRestrictions(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("Keystone v3 only")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	validate := func(token string) (userID, expiresAt string) {
+		req, err := http.NewRequest(http.MethodGet, c.AuthUrl+"/auth/tokens?nocatalog", nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", token)
+		req.Header.Set("X-Subject-Token", token)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		var payload struct {
+			Token struct {
+				ExpiresAt string `json:"expires_at"`
+				User      struct {
+					ID string `json:"id"`
+				} `json:"user"`
+			} `json:"token"`
+		}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&payload))
+		return payload.Token.User.ID, payload.Token.ExpiresAt
+	}
+	create := func(token, userID, name, expiresAt string) *http.Response {
+		body := strings.NewReader(`{"application_credential": {"name": "` + name + `", "expires_at": "` + expiresAt + `"}}`)
+		req, err := http.NewRequest(http.MethodPost, c.AuthUrl+"/users/"+userID+"/application_credentials", body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Auth-Token", token)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	//
+
+	userID, _ := validate(c.AuthToken)
+	expiresAt := time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+
+	res := create(c.AuthToken, userID, "ci", expiresAt)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var credential struct {
+		ApplicationCredential struct {
+			ID     string `json:"id"`
+			Secret string `json:"secret"`
+		} `json:"application_credential"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&credential)
+	assert.NoError(t, err)
+
+	app := &swift.Connection{
+		AuthUrl:                     c.AuthUrl,
+		ApplicationCredentialId:     credential.ApplicationCredential.ID,
+		ApplicationCredentialSecret: credential.ApplicationCredential.Secret,
+		Region:                      c.Region,
+	}
+	err = app.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	// The token does not outlive the credential.
+	_, tokenExpiresAt := validate(app.AuthToken)
+	assert.Equal(t, expiresAt, tokenExpiresAt)
+
+	// The token can not create other credentials, even once rescoped.
+	res = create(app.AuthToken, userID, "other", "")
+	res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	rescoped := &swift.Connection{
+		AuthUrl: c.AuthUrl,
+		Token:   app.AuthToken,
+		Tenant:  c.Tenant,
+		Domain:  c.Domain,
+		Region:  c.Region,
+	}
+	err = rescoped.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	res = create(rescoped.AuthToken, userID, "other", "")
+	res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
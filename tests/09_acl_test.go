@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestACLPublicRead(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "public", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	status := func(method, path, referrer string) int {
+		req, err := http.NewRequest(method, c.StorageUrl+path, nil)
+		assert.NoError(t, err)
+		if referrer != "" {
+			req.Header.Set("Referer", referrer)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodGet, "/Xcontainer/a1/b2/c3.txt", ""))
+
+	//
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Read": ".r:*"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, status(http.MethodGet, "/Xcontainer/a1/b2/c3.txt", ""))
+	assert.Equal(t, http.StatusOK, status(http.MethodHead, "/Xcontainer/a1/b2/c3.txt", ""))
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodGet, "/Xcontainer", ""))
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodPut, "/Xcontainer/a1/b2/c3.txt", ""))
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodDelete, "/Xcontainer/a1/b2/c3.txt", ""))
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodGet, "", ""))
+
+	//
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Read": ".r:*,.rlistings"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, status(http.MethodGet, "/Xcontainer", ""))
+
+	//
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Read": ".r:.example.com,.r:-evil.example.com"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, status(http.MethodGet, "/Xcontainer/a1/b2/c3.txt", "https://www.example.com/index.html"))
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodGet, "/Xcontainer/a1/b2/c3.txt", "https://evil.example.com/index.html"))
+	assert.Equal(t, http.StatusUnauthorized, status(http.MethodGet, "/Xcontainer/a1/b2/c3.txt", ""))
+}
+
+func TestACLCrossAccountWrite(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+	other.StorageUrl = c.StorageUrl
+
+	//
+
+	err = other.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "visitor", "text/plain")
+	assert.Equal(t, swift.Forbidden, err)
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Write": "other:visitor"})
+	assert.NoError(t, err)
+
+	err = other.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "visitor", "text/plain")
+	assert.NoError(t, err)
+
+	_, err = other.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.Equal(t, swift.Forbidden, err)
+
+	err = other.ContainerDelete(ctx, "Xcontainer")
+	assert.Equal(t, swift.Forbidden, err)
+
+	//
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Read": "other:*"})
+	assert.NoError(t, err)
+
+	payload, err := other.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "visitor", payload)
+
+	payload, err = c.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "visitor", payload)
+
+	// The project name only designates the project of the container's domain.
+	if authVersion == 3 {
+		intruder := as(c, 2)
+		err = intruder.Authenticate(ctx)
+		assert.NoError(t, err)
+		account := path.Base(intruder.StorageUrl)
+		intruder.StorageUrl = c.StorageUrl
+
+		_, err = intruder.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+		assert.Equal(t, swift.Forbidden, err)
+
+		err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Read": account + ":*"})
+		assert.NoError(t, err)
+
+		payload, err = intruder.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "visitor", payload)
+	}
+}
+
+func TestACLLargeObjectSegments(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Read": "other:*"})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Chunks-Container", swift.Headers{})
+	assert.NoError(t, err)
+
+	err = c.ObjectPutString(ctx, "Chunks-Container", "segments/s1", "secret", "text/plain")
+	assert.NoError(t, err)
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "dlo", strings.NewReader(""), false, "", "", swift.Headers{
+		"X-Object-Manifest": "Chunks-Container/segments/",
+	})
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodPut, c.StorageUrl+"/Xcontainer/slo?multipart-manifest=put", strings.NewReader(`[{"path": "/Chunks-Container/segments/s1"}]`))
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	//
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+	other.StorageUrl = c.StorageUrl
+
+	status := func(method, object string) int {
+		req, err := http.NewRequest(method, c.StorageUrl+"/Xcontainer/"+object, nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", other.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, status(http.MethodGet, "dlo"))
+	assert.Equal(t, http.StatusForbidden, status(http.MethodHead, "dlo"))
+	assert.Equal(t, http.StatusConflict, status(http.MethodGet, "slo"))
+	assert.Equal(t, http.StatusConflict, status(http.MethodHead, "slo"))
+
+	//
+
+	err = c.ContainerUpdate(ctx, "Chunks-Container", swift.Headers{"X-Container-Read": "other:*"})
+	assert.NoError(t, err)
+
+	payload, err := other.ObjectGetString(ctx, "Xcontainer", "dlo")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", payload)
+
+	payload, err = other.ObjectGetString(ctx, "Xcontainer", "slo")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", payload)
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTempURLDownload(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Meta-Temp-URL-Key": "secret"})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "signed", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	link := c.ObjectTempUrl("Xcontainer", "a1/b2/c3.txt", "secret", http.MethodGet, time.Now().Add(time.Minute))
+	res, err := http.Get(link)
+	assert.NoError(t, err)
+	payload, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "signed", string(payload))
+	assert.Equal(t, `attachment; filename=c3.txt`, res.Header.Get("Content-Disposition"))
+
+	res, err = http.Head(link)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get(link + "&filename=report.txt&inline")
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `inline; filename=report.txt`, res.Header.Get("Content-Disposition"))
+
+	// The stored Content-Disposition replaces the default one but not the requested one.
+	err = c.ObjectUpdate(ctx, "Xcontainer", "a1/b2/c3.txt", swift.Headers{"Content-Disposition": `attachment; filename="stored.txt"`})
+	assert.NoError(t, err)
+
+	res, err = http.Get(link)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, `attachment; filename="stored.txt"`, res.Header.Get("Content-Disposition"))
+
+	res, err = http.Get(link + "&filename=report.txt")
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, `attachment; filename=report.txt`, res.Header.Get("Content-Disposition"))
+
+	res, err = http.Head(link + "&inline")
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, `inline`, res.Header.Get("Content-Disposition"))
+
+	//
+
+	link = c.ObjectTempUrl("Xcontainer", "a1/b2/c3.txt", "wrong", http.MethodGet, time.Now().Add(time.Minute))
+	res, err = http.Get(link)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	link = c.ObjectTempUrl("Xcontainer", "a1/b2/c3.txt", "secret", http.MethodGet, time.Now().Add(-time.Minute))
+	res, err = http.Get(link)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	link = c.ObjectTempUrl("Xcontainer", "a1/b2/c3.txt", "secret", http.MethodPut, time.Now().Add(time.Minute))
+	res, err = http.Get(link)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestTempURLUpload(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Meta-Temp-URL-Key": "secret"})
+	assert.NoError(t, err)
+
+	//
+
+	link := c.ObjectTempUrl("Xcontainer", "upload.txt", "secret", http.MethodPut, time.Now().Add(time.Minute))
+	req, err := http.NewRequest(http.MethodPut, link, strings.NewReader("uploaded"))
+	assert.NoError(t, err)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "upload.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "uploaded", payload)
+
+	// The signed object can not be a copy, a symlink or a manifest of other objects.
+	err = c.ObjectPutString(ctx, "Xcontainer", "secret.txt", "secret", "text/plain")
+	assert.NoError(t, err)
+
+	for header, value := range map[string]string{
+		"X-Copy-From":       "Xcontainer/secret.txt",
+		"X-Symlink-Target":  "Xcontainer/secret.txt",
+		"X-Object-Manifest": "Xcontainer/secret",
+	} {
+		req, err = http.NewRequest(http.MethodPut, link, strings.NewReader(""))
+		assert.NoError(t, err)
+		req.Header.Set(header, value)
+		res, err = http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, header)
+	}
+
+	req, err = http.NewRequest(http.MethodPut, link+"&multipart-manifest=put", strings.NewReader(`[{"path": "/Xcontainer/secret.txt"}]`))
+	assert.NoError(t, err)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	payload, err = c.ObjectGetString(ctx, "Xcontainer", "upload.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "uploaded", payload)
+}
+
+func TestTempURLDigestsPrefixAndIP(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Meta-Temp-URL-Key-2": "secret"})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "signed", "text/plain")
+	assert.NoError(t, err)
+
+	u, err := url.Parse(c.StorageUrl)
+	assert.NoError(t, err)
+	expires := time.Now().Add(time.Minute).Unix()
+
+	get := func(object, query string) int {
+		res, err := http.Get(c.StorageUrl + "/Xcontainer/" + object + "?" + query)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// SHA256 hexadecimal signature
+	mac := hmac.New(sha256.New, []byte("secret"))
+	fmt.Fprintf(mac, "GET\n%d\n%s/Xcontainer/a1/b2/c3.txt", expires, u.Path)
+	query := fmt.Sprintf("temp_url_sig=%s&temp_url_expires=%d", hex.EncodeToString(mac.Sum(nil)), expires)
+	assert.Equal(t, http.StatusOK, get("a1/b2/c3.txt", query))
+
+	// SHA512 base64 signature with prefix
+	mac = hmac.New(sha512.New, []byte("secret"))
+	fmt.Fprintf(mac, "GET\n%d\nprefix:%s/Xcontainer/a1/", expires, u.Path)
+	signature := "sha512:" + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	query = fmt.Sprintf("temp_url_sig=%s&temp_url_expires=%d&temp_url_prefix=a1/", signature, expires)
+	assert.Equal(t, http.StatusOK, get("a1/b2/c3.txt", query))
+	assert.Equal(t, http.StatusUnauthorized, get("other.txt", query))
+
+	// IP restriction
+	for iprange, status := range map[string]int{"127.0.0.0/8": http.StatusOK, "10.0.0.1": http.StatusUnauthorized} {
+		mac = hmac.New(sha256.New, []byte("secret"))
+		fmt.Fprintf(mac, "ip=%s\nGET\n%d\n%s/Xcontainer/a1/b2/c3.txt", iprange, expires, u.Path)
+		query = fmt.Sprintf("temp_url_sig=%s&temp_url_expires=%d&temp_url_ip_range=%s", hex.EncodeToString(mac.Sum(nil)), expires, iprange)
+		assert.Equal(t, status, get("a1/b2/c3.txt", query))
+	}
+}
+
+func TestTempURLAccountKey(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.AccountUpdate(ctx, swift.Headers{"X-Account-Meta-Temp-URL-Key": "secret"})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "signed", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	link := c.ObjectTempUrl("Xcontainer", "a1/b2/c3.txt", "secret", http.MethodGet, time.Now().Add(time.Minute))
+	res, err := http.Get(link)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	err = c.AccountUpdate(ctx, swift.Headers{"X-Account-Meta-Temp-URL-Key": "rotated"})
+	assert.NoError(t, err)
+
+	res, err = http.Get(link)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
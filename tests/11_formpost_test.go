@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func formpost(t *testing.T, c *swift.Connection, prefix, key, redirect string, maxsize, maxcount int, expires time.Time, files map[string]string) *http.Response {
+	u, err := url.Parse(c.StorageUrl)
+	assert.NoError(t, err)
+
+	target := "/Xcontainer"
+	if prefix != "" {
+		target += "/" + prefix
+	}
+
+	mac := hmac.New(sha1.New, []byte(key))
+	fmt.Fprintf(mac, "%s%s\n%s\n%d\n%d\n%d", u.Path, target, redirect, maxsize, maxcount, expires.Unix())
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("redirect", redirect)
+	w.WriteField("max_file_size", strconv.Itoa(maxsize))
+	w.WriteField("max_file_count", strconv.Itoa(maxcount))
+	w.WriteField("expires", strconv.FormatInt(expires.Unix(), 10))
+	w.WriteField("signature", hex.EncodeToString(mac.Sum(nil)))
+	i := 0
+	for filename, content := range files {
+		i++
+		fw, err := w.CreateFormFile("file"+strconv.Itoa(i), filename)
+		assert.NoError(t, err)
+		fw.Write([]byte(content))
+	}
+	w.Close()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Post(c.StorageUrl+target, w.FormDataContentType(), &body)
+	assert.NoError(t, err)
+	res.Body.Close()
+	return res
+}
+
+func TestFormPost(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Meta-Temp-URL-Key": "secret"})
+	assert.NoError(t, err)
+
+	expires := time.Now().Add(time.Minute)
+
+	//
+
+	res := formpost(t, c, "uploads/", "secret", "", 1024, 2, expires, map[string]string{"a.txt": "aaa", "b.txt": "bbb"})
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "uploads/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "aaa", payload)
+	payload, err = c.ObjectGetString(ctx, "Xcontainer", "uploads/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "bbb", payload)
+
+	res = formpost(t, c, "", "secret", "https://example.com/done?id=42", 1024, 1, expires, map[string]string{"c.txt": "ccc"})
+	assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+	assert.Equal(t, "https://example.com/done?id=42&status=201&message=", res.Header.Get("Location"))
+
+	payload, err = c.ObjectGetString(ctx, "Xcontainer", "c.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "ccc", payload)
+
+	// An overwritten object does not keep its metadata.
+	err = c.ObjectPutString(ctx, "Xcontainer", "c.txt", "old", "text/plain")
+	assert.NoError(t, err)
+	err = c.ObjectUpdate(ctx, "Xcontainer", "c.txt", swift.Headers{"X-Object-Meta-Color": "blue"})
+	assert.NoError(t, err)
+
+	res = formpost(t, c, "", "secret", "", 1024, 1, expires, map[string]string{"c.txt": "ccc"})
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	_, headers, err := c.Object(ctx, "Xcontainer", "c.txt")
+	assert.NoError(t, err)
+	assert.Empty(t, headers.ObjectMetadata())
+
+	//
+
+	res = formpost(t, c, "", "secret", "https://example.com/done", 2, 1, expires, map[string]string{"d.txt": "ddd"})
+	assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+	assert.Equal(t, "https://example.com/done?status=400&message=max_file_size+exceeded", res.Header.Get("Location"))
+
+	res = formpost(t, c, "", "secret", "", 1024, 1, expires, map[string]string{"d.txt": "ddd", "e.txt": "eee"})
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// The body is not read beyond the signed files.
+	res = formpost(t, c, "", "secret", "https://example.com/done", 2, 1, expires, map[string]string{"d.txt": strings.Repeat("d", 1<<20)})
+	assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+	assert.Equal(t, "https://example.com/done?status=400&message=max_file_size+exceeded", res.Header.Get("Location"))
+
+	res = formpost(t, c, "", "wrong", "", 1024, 1, expires, map[string]string{"d.txt": "ddd"})
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res = formpost(t, c, "", "secret", "", 1024, 1, time.Now().Add(-time.Minute), map[string]string{"d.txt": "ddd"})
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "d.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+}
+
+func TestFormPostAttributes(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Meta-Temp-URL-Key": "secret"})
+	assert.NoError(t, err)
+
+	u, err := url.Parse(c.StorageUrl)
+	assert.NoError(t, err)
+	expires := time.Now().Add(time.Minute).Unix()
+
+	mac := hmac.New(sha1.New, []byte("secret"))
+	fmt.Fprintf(mac, "%s/Xcontainer\n\n1024\n1\n%d", u.Path, expires)
+
+	post := func(token string, fields func(w *multipart.Writer)) int {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+		fields(w)
+		w.Close()
+
+		req, err := http.NewRequest(http.MethodPost, c.StorageUrl+"/Xcontainer", &body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		if token != "" {
+			req.Header.Set("X-Auth-Token", token)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// The signature must precede the files.
+	status := post("", func(w *multipart.Writer) {
+		fw, err := w.CreateFormFile("file", "a.txt")
+		assert.NoError(t, err)
+		fw.Write([]byte("aaa"))
+		w.WriteField("redirect", "")
+		w.WriteField("max_file_size", "1024")
+		w.WriteField("max_file_count", "1")
+		w.WriteField("expires", strconv.FormatInt(expires, 10))
+		w.WriteField("signature", hex.EncodeToString(mac.Sum(nil)))
+	})
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "a.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	// The account owner does not need a signature.
+	status = post(c.AuthToken, func(w *multipart.Writer) {
+		w.WriteField("max_file_size", "1024")
+		w.WriteField("max_file_count", "1")
+		fw, err := w.CreateFormFile("file", "b.txt")
+		assert.NoError(t, err)
+		fw.Write([]byte("bbb"))
+	})
+	assert.Equal(t, http.StatusCreated, status)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "bbb", payload)
+}
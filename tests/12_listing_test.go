@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListingObjects(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	for _, name := range []string{"a.txt", "b/1.txt", "b/2.txt", "b/c/3.txt", "d.txt", "e/4.txt"} {
+		err = c.ObjectPutString(ctx, "Xcontainer", name, name, "text/plain")
+		assert.NoError(t, err)
+	}
+
+	names := func(opts *swift.ObjectsOpts) []string {
+		objects, err := c.Objects(ctx, "Xcontainer", opts)
+		assert.NoError(t, err)
+
+		names := make([]string, 0, len(objects))
+		for _, object := range objects {
+			names = append(names, object.Name)
+		}
+		return names
+	}
+
+	//
+
+	assert.Equal(t, []string{"a.txt", "b/1.txt", "b/2.txt", "b/c/3.txt", "d.txt", "e/4.txt"}, names(nil))
+	assert.Equal(t, []string{"a.txt", "b/1.txt"}, names(&swift.ObjectsOpts{Limit: 2}))
+	assert.Equal(t, []string{"b/2.txt", "b/c/3.txt", "d.txt"}, names(&swift.ObjectsOpts{Marker: "b/1.txt", EndMarker: "e/4.txt"}))
+	assert.Equal(t, []string{"b/1.txt", "b/2.txt", "b/c/3.txt"}, names(&swift.ObjectsOpts{Prefix: "b/"}))
+
+	// Delimiter
+	assert.Equal(t, []string{"a.txt", "b/", "d.txt", "e/"}, names(&swift.ObjectsOpts{Delimiter: '/'}))
+	assert.Equal(t, []string{"b/1.txt", "b/2.txt", "b/c/"}, names(&swift.ObjectsOpts{Prefix: "b/", Delimiter: '/'}))
+	assert.Equal(t, []string{"a.txt", "b/"}, names(&swift.ObjectsOpts{Delimiter: '/', Limit: 2}))
+	assert.Equal(t, []string{"d.txt", "e/"}, names(&swift.ObjectsOpts{Delimiter: '/', Marker: "b/"}))
+
+	objects, err := c.Objects(ctx, "Xcontainer", &swift.ObjectsOpts{Delimiter: '/'})
+	assert.NoError(t, err)
+	if assert.Len(t, objects, 4) {
+		assert.False(t, objects[0].PseudoDirectory)
+		assert.True(t, objects[1].PseudoDirectory)
+	}
+
+	// Path
+	assert.Equal(t, []string{"b/1.txt", "b/2.txt"}, names(&swift.ObjectsOpts{Path: "b"}))
+
+	// Reverse
+	assert.Equal(t, []string{"e/4.txt", "d.txt", "b/c/3.txt"}, listing(t, c, "/Xcontainer", "reverse=true&limit=3"))
+	assert.Equal(t, []string{"d.txt", "b/", "a.txt"}, listing(t, c, "/Xcontainer", "reverse=true&delimiter=/&marker=e"))
+}
+
+func TestListingContainers(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"alpha", "beta", "photos-2020", "photos-2021", "zeta"} {
+		err = c.ContainerCreate(ctx, name, swift.Headers{})
+		assert.NoError(t, err)
+	}
+
+	names := func(opts *swift.ContainersOpts) []string {
+		containers, err := c.Containers(ctx, opts)
+		assert.NoError(t, err)
+
+		names := make([]string, 0, len(containers))
+		for _, container := range containers {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+
+	//
+
+	assert.Equal(t, []string{"alpha", "beta", "photos-2020", "photos-2021", "zeta"}, names(nil))
+	assert.Equal(t, []string{"beta", "photos-2020"}, names(&swift.ContainersOpts{Marker: "alpha", Limit: 2}))
+	assert.Equal(t, []string{"alpha", "beta"}, names(&swift.ContainersOpts{EndMarker: "photos"}))
+	assert.Equal(t, []string{"photos-2020", "photos-2021"}, names(&swift.ContainersOpts{Prefix: "photos"}))
+	assert.Equal(t, []string{"alpha", "beta", "photos-", "zeta"}, listing(t, c, "", "delimiter=-"))
+	assert.Equal(t, []string{"zeta", "photos-2021"}, listing(t, c, "", "reverse=on&limit=2"))
+}
+
+// listing returns the names and subdirs of the JSON listing of the given path.
+func listing(t *testing.T, c *swift.Connection, path, query string) []string {
+	req, err := http.NewRequest(http.MethodGet, c.StorageUrl+path+"?format=json&"+query, nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	var entries []map[string]interface{}
+	err = json.NewDecoder(res.Body).Decode(&entries)
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if subdir, ok := entry["subdir"]; ok {
+			names = append(names, subdir.(string))
+			continue
+		}
+		names = append(names, entry["name"].(string))
+	}
+	return names
+}
+
+func TestListingFormats(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	get := func(path, query, accept string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, c.StorageUrl+path+"?"+query, nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	res := get("", "", "")
+	res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "tester", "text/plain")
+	assert.NoError(t, err)
+
+	// Plain text by default
+	containers, err := c.ContainerNames(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Xcontainer"}, containers)
+
+	objects, err := c.ObjectNames(ctx, "Xcontainer", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1/b2/c3.txt"}, objects)
+
+	// XML
+	for _, tc := range []struct{ query, accept, mediatype string }{
+		{"format=xml", "", "application/xml"},
+		{"", "text/xml", "text/xml"},
+		{"", "application/json;q=0.5, application/xml;q=0.9", "application/xml"},
+	} {
+		res = get("/Xcontainer", tc.query+"&delimiter=/", tc.accept)
+		payload, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.True(t, strings.HasPrefix(res.Header.Get("Content-Type"), tc.mediatype), res.Header.Get("Content-Type"))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<container name="Xcontainer"><subdir name="a1/"><name>a1/</name></subdir></container>`, string(payload))
+	}
+
+	res = get("", "format=xml", "")
+	payload, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.NoError(t, err)
+	assert.Contains(t, string(payload), `<account name="AUTH_`)
+	assert.Contains(t, string(payload), `<container><name>Xcontainer</name><count>`)
+
+	// Negotiation
+	res = get("", "", "application/json, text/plain;q=0.1")
+	res.Body.Close()
+	assert.True(t, strings.HasPrefix(res.Header.Get("Content-Type"), "application/json"))
+
+	res = get("", "format=plain", "application/json")
+	res.Body.Close()
+	assert.True(t, strings.HasPrefix(res.Header.Get("Content-Type"), "text/plain"))
+
+	res = get("", "", "image/png")
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotAcceptable, res.StatusCode)
+}
+
+func TestListingLargeSubdir(t *testing.T) {
+	if authVersion != 3 {
+		t.Skip("The pages do not depend on the auth version")
+	}
+
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	// The subdir's records span two listing pages.
+	files := map[string]string{"a.txt": "a", "c.txt": "c"}
+	for i := range 1100 {
+		files[fmt.Sprintf("b/%04d.txt", i)] = "b"
+	}
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	result, err := c.BulkUpload(ctx, "Xcontainer", tarball(t, files, false), swift.UploadTar, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(files), result.NumberCreated)
+
+	//
+
+	assert.Equal(t, []string{"a.txt", "b/", "c.txt"}, listing(t, c, "/Xcontainer", "delimiter=/"))
+	assert.Equal(t, []string{"c.txt", "b/", "a.txt"}, listing(t, c, "/Xcontainer", "delimiter=/&reverse=true"))
+	assert.Equal(t, []string{"b/", "c.txt"}, listing(t, c, "/Xcontainer", "delimiter=/&marker=a.txt"))
+	assert.Equal(t, []string{"c.txt"}, listing(t, c, "/Xcontainer", "delimiter=/&marker=b/"))
+}
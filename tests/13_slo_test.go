@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticLargeObject(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Chunks-Container", swift.Headers{})
+	assert.NoError(t, err)
+
+	content := strings.Repeat(time.Now().Format(time.RFC3339), 100<<10) // 2.5MiB
+
+	slo, err := c.StaticLargeObjectCreate(ctx, &swift.LargeObjectOpts{
+		Container:        "Xcontainer",
+		ObjectName:       "a42/dates.txt",
+		ContentType:      "text/plain",
+		SegmentContainer: "Chunks-Container",
+		SegmentPrefix:    "a42",
+		ChunkSize:        1 << 20, // 1 MiB
+	})
+	assert.NoError(t, err)
+
+	n, err := io.Copy(slo, bytes.NewBufferString(content))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+
+	err = slo.Close()
+	assert.NoError(t, err)
+
+	//
+
+	info, headers, err := c.Object(ctx, "Xcontainer", "a42/dates.txt")
+	assert.NoError(t, err)
+	assert.True(t, headers.IsLargeObjectSLO())
+	assert.Equal(t, "True", headers["X-Static-Large-Object"])
+	assert.Equal(t, int64(len(content)), info.Bytes)
+	assert.Contains(t, info.ContentType, "text/plain")
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "a42/dates.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, content, payload)
+
+	res := rangeRequest(t, c, "a42/dates.txt", "bytes=1048570-1048589")
+	payload = readAll(t, res)
+	assert.Equal(t, http.StatusPartialContent, res.StatusCode)
+	assert.Equal(t, content[1048570:1048590], payload)
+
+	//
+
+	// A copy is a plain object with the content of the large object.
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a42/dates.txt", "Xcontainer", "dates.txt", nil)
+	assert.NoError(t, err)
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "dates.txt")
+	assert.NoError(t, err)
+	assert.False(t, headers.IsLargeObjectSLO())
+
+	payload, err = c.ObjectGetString(ctx, "Xcontainer", "dates.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, content, payload)
+
+	//
+
+	err = c.StaticLargeObjectDelete(ctx, "Xcontainer", "a42/dates.txt")
+	assert.NoError(t, err)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "a42/dates.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	segments, err := c.ObjectNames(ctx, "Chunks-Container", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, segments)
+}
+
+func TestStaticLargeObjectManifest(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Chunks-Container", swift.Headers{})
+	assert.NoError(t, err)
+
+	err = c.ObjectPutString(ctx, "Chunks-Container", "s1", "0123456789", "text/plain")
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Chunks-Container", "s2", "abcdefghij", "text/plain")
+	assert.NoError(t, err)
+
+	manifest := func(method, object, query, body string) *http.Response {
+		req, err := http.NewRequest(method, c.StorageUrl+"/Xcontainer/"+object+"?"+query, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	// Invalid manifests
+	for _, body := range []string{
+		`{"path": "/Chunks-Container/s1"}`,
+		`[]`,
+		`[{"path": "/Chunks-Container/s1", "etag": "d41d8cd98f00b204e9800998ecf8427e"}]`,
+		`[{"path": "/Chunks-Container/s1", "size_bytes": 42}]`,
+		`[{"path": "/Chunks-Container/unknown"}]`,
+		`[{"path": "/Chunks-Container/s1", "range": "20-"}]`,
+		`[{"path": "/Xcontainer/slo"}]`,
+	} {
+		res := manifest(http.MethodPut, "slo", "multipart-manifest=put", body)
+		res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+	}
+
+	_, _, err = c.Object(ctx, "Xcontainer", "slo")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	// Ranged and nested segments
+	res := manifest(http.MethodPut, "nested", "multipart-manifest=put", `[
+		{"path": "/Chunks-Container/s2", "etag": "a925576942e94b2ef57a066101b48876", "size_bytes": 10, "range": "-3"}
+	]`)
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res = manifest(http.MethodPut, "slo", "multipart-manifest=put", `[
+		{"path": "/Chunks-Container/s1", "etag": "781e5e245d69b566979b86e28d23f2c7", "size_bytes": 10},
+		{"path": "Chunks-Container/s2", "etag": null, "size_bytes": null, "range": "2-4"},
+		{"path": "/Xcontainer/nested"}
+	]`)
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "slo")
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789cdehij", payload)
+
+	res = rangeRequest(t, c, "slo", "bytes=8-12")
+	assert.Equal(t, http.StatusPartialContent, res.StatusCode)
+	assert.Equal(t, "89cde", readAll(t, res))
+
+	// Manifest listing
+	res = manifest(http.MethodGet, "slo", "multipart-manifest=get", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "True", res.Header.Get("X-Static-Large-Object"))
+
+	var segments []map[string]interface{}
+	err = json.NewDecoder(res.Body).Decode(&segments)
+	res.Body.Close()
+	assert.NoError(t, err)
+	assert.Len(t, segments, 3)
+	assert.Equal(t, "/Chunks-Container/s2", segments[1]["name"])
+	assert.Equal(t, "2-4", segments[1]["range"])
+	assert.Equal(t, float64(10), segments[1]["bytes"])
+
+	res = manifest(http.MethodGet, "slo", "multipart-manifest=get&format=raw", "")
+	err = json.NewDecoder(res.Body).Decode(&segments)
+	res.Body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "/Chunks-Container/s1", segments[0]["path"])
+	assert.Equal(t, "781e5e245d69b566979b86e28d23f2c7", segments[0]["etag"])
+
+	// A changed segment is detected
+	err = c.ObjectPutString(ctx, "Chunks-Container", "s1", "9876543210", "text/plain")
+	assert.NoError(t, err)
+
+	res = manifest(http.MethodGet, "slo", "", "")
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+	// Deletion of the segments, s2 is shared with the nested manifest so it is not found the second time
+	res = manifest(http.MethodDelete, "slo", "multipart-manifest=delete", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, readAll(t, res), "Number Deleted: 4\nNumber Not Found: 1\n")
+
+	for _, object := range []string{"slo", "nested"} {
+		_, _, err = c.Object(ctx, "Xcontainer", object)
+		assert.Equal(t, swift.ObjectNotFound, err)
+	}
+	names, err := c.ObjectNames(ctx, "Chunks-Container", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+// readAll returns the body of the response.
+func readAll(t *testing.T, res *http.Response) string {
+	defer res.Body.Close()
+
+	payload, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	return string(payload)
+}
+
+func TestStaticLargeObjectDepth(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "s0", "0123456789", "text/plain")
+	assert.NoError(t, err)
+
+	manifest := func(method, object, query, body string) *http.Response {
+		req, err := http.NewRequest(method, c.StorageUrl+"/Xcontainer/"+object+"?"+query, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	// s1 is made of an object, s10 is nested 10 times.
+	for i := 1; i <= 10; i++ {
+		res := manifest(http.MethodPut, fmt.Sprintf("s%d", i), "multipart-manifest=put", fmt.Sprintf(`[{"path": "/Xcontainer/s%d"}]`, i-1))
+		res.Body.Close()
+		assert.Equal(t, http.StatusCreated, res.StatusCode, i)
+	}
+
+	res := manifest(http.MethodPut, "s11", "multipart-manifest=put", `[{"path": "/Xcontainer/s10"}]`)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	assert.Contains(t, readAll(t, res), "max recursion depth exceeded")
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "s10")
+	assert.NoError(t, err)
+	assert.Equal(t, "0123456789", payload)
+
+	res = rangeRequest(t, c, "s10", "bytes=2-4")
+	assert.Equal(t, http.StatusPartialContent, res.StatusCode)
+	assert.Equal(t, "234", readAll(t, res))
+
+	//
+
+	res = manifest(http.MethodDelete, "s10", "multipart-manifest=delete", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, readAll(t, res), "Number Deleted: 11\n")
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioningStack(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Versions", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{"X-Versions-Location": "Versions"})
+	assert.NoError(t, err)
+
+	_, headers, err := c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, "Versions", headers["X-Versions-Location"])
+
+	for _, content := range []string{"v1", "v2", "v3"} {
+		err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", content, "text/plain")
+		assert.NoError(t, err)
+	}
+
+	versions, err := c.ObjectNames(ctx, "Versions", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 2) {
+		assert.Regexp(t, `^00ca1/b2/c3.txt/\d{10}\.\d{5}$`, versions[0])
+	}
+
+	payload, err := c.ObjectGetString(ctx, "Versions", versions[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", payload)
+
+	//
+
+	// Each deletion restores the previous version.
+	for _, expected := range []string{"v2", "v1"} {
+		err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c3.txt")
+		assert.NoError(t, err)
+
+		payload, err = c.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, payload)
+	}
+
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	versions, err = c.ObjectNames(ctx, "Versions", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	//
+
+	// The versioning is disabled by the removal header.
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Remove-Versions-Location": "x"})
+	assert.NoError(t, err)
+
+	for _, content := range []string{"v1", "v2"} {
+		err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", content, "text/plain")
+		assert.NoError(t, err)
+	}
+
+	versions, err = c.ObjectNames(ctx, "Versions", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestVersioningHistory(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-History-Location": "History"})
+	assert.NoError(t, err)
+
+	// The versions container must exist.
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "v1", "text/plain")
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "v2", "text/plain")
+	if assert.Error(t, err) {
+		assert.Equal(t, 412, err.(*swift.Error).StatusCode)
+	}
+
+	err = c.ContainerCreate(ctx, "History", swift.Headers{})
+	assert.NoError(t, err)
+
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "v2", "text/plain")
+	assert.NoError(t, err)
+
+	//
+
+	// The deletion archives the object followed by a delete marker.
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	objects, err := c.Objects(ctx, "History", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, objects, 3) {
+		for i, expected := range []string{"v1", "v2", ""} {
+			payload, err := c.ObjectGetString(ctx, "History", objects[i].Name)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, payload, fmt.Sprintf("version %d", i))
+		}
+		assert.Equal(t, "application/x-deleted;swift_versions_deleted=1", objects[2].ContentType)
+	}
+
+	// Both modes can not be enabled at once.
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-History-Location": "History", "X-Versions-Location": "History"})
+	if assert.Error(t, err) {
+		assert.Equal(t, 400, err.(*swift.Error).StatusCode)
+	}
+}
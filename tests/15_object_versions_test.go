@@ -0,0 +1,367 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectVersions(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	request := func(method, path, query string) *http.Response {
+		req, err := http.NewRequest(method, c.StorageUrl+"/Xcontainer"+path+"?"+query, nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	listing := func() []map[string]interface{} {
+		var versions []map[string]interface{}
+		res := request(http.MethodGet, "", "versions&format=json")
+		err := json.NewDecoder(res.Body).Decode(&versions)
+		res.Body.Close()
+		assert.NoError(t, err)
+		return versions
+	}
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	// The object created before the versioning has the null version.
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1/b2/c3.txt", "v0", "text/plain")
+	assert.NoError(t, err)
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Versions-Enabled": "true"})
+	assert.NoError(t, err)
+
+	_, headers, err := c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, "True", headers["X-Versions-Enabled"])
+
+	var vids []string
+	for _, content := range []string{"v1", "v2"} {
+		headers, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader(content), false, "", "text/plain", nil)
+		assert.NoError(t, err)
+		assert.Regexp(t, `^\d{10}\.\d{5}$`, headers["X-Object-Version-Id"])
+		vids = append(vids, headers["X-Object-Version-Id"])
+	}
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, vids[1], headers["X-Object-Version-Id"])
+
+	versions := listing()
+	if assert.Len(t, versions, 3) {
+		for i, vid := range []string{vids[1], vids[0], "null"} {
+			assert.Equal(t, "a1/b2/c3.txt", versions[i]["name"])
+			assert.Equal(t, vid, versions[i]["version_id"])
+			assert.Equal(t, i == 0, versions[i]["is_latest"])
+		}
+	}
+
+	// The previous versions are downloaded by their identifier.
+	for vid, expected := range map[string]string{"null": "v0", vids[0]: "v1", vids[1]: "v2"} {
+		res := request(http.MethodGet, "/a1/b2/c3.txt", "version-id="+vid)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, vid, res.Header.Get("X-Object-Version-Id"))
+		assert.Equal(t, expected, readAll(t, res))
+	}
+
+	res := request(http.MethodHead, "/a1/b2/c3.txt", "version-id=0000000000.00000")
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	//
+
+	// The deletion adds a delete marker as the latest version.
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	versions = listing()
+	if assert.Len(t, versions, 4) {
+		assert.Equal(t, "application/x-deleted;swift_versions_deleted=1", versions[0]["content_type"])
+		assert.Equal(t, true, versions[0]["is_latest"])
+		assert.Equal(t, vids[1], versions[1]["version_id"])
+
+		// A container can not be deleted while it has versions.
+		err = c.ContainerDelete(ctx, "Xcontainer")
+		assert.Equal(t, swift.ContainerNotEmpty, err)
+
+		// The removal of the delete marker restores the previous version.
+		res = request(http.MethodDelete, "/a1/b2/c3.txt", "version-id="+versions[0]["version_id"].(string))
+		res.Body.Close()
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	}
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", payload)
+
+	// The removal of a previous version keeps the current version.
+	res = request(http.MethodDelete, "/a1/b2/c3.txt", "version-id="+vids[0])
+	res.Body.Close()
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	versions = listing()
+	if assert.Len(t, versions, 2) {
+		assert.Equal(t, vids[1], versions[0]["version_id"])
+		assert.Equal(t, "null", versions[1]["version_id"])
+	}
+
+	//
+
+	// The removal of the current version restores the previous one.
+	for _, vid := range []string{vids[1], "null"} {
+		res = request(http.MethodDelete, "/a1/b2/c3.txt", "version-id="+vid)
+		res.Body.Close()
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	}
+
+	assert.Empty(t, listing())
+
+	err = c.ContainerDelete(ctx, "Xcontainer")
+	assert.NoError(t, err)
+}
+
+func TestObjectVersionsLegacy(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Versions", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{"X-Versions-Location": "Versions"})
+	assert.NoError(t, err)
+
+	// The object versioning and the legacy versioning can not be enabled at once.
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Versions-Enabled": "true"})
+	if assert.Error(t, err) {
+		assert.Equal(t, 400, err.(*swift.Error).StatusCode)
+	}
+
+	err = c.ContainerCreate(ctx, "Ycontainer", swift.Headers{"X-Versions-Enabled": "true"})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Ycontainer", swift.Headers{"X-Versions-Location": "Versions"})
+	if assert.Error(t, err) {
+		assert.Equal(t, 400, err.(*swift.Error).StatusCode)
+	}
+}
+
+func TestObjectVersionsMetadata(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	request := func(method, query string) *http.Response {
+		req, err := http.NewRequest(method, c.StorageUrl+"/Xcontainer/a1/b2/c3.txt?"+query, nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res
+	}
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Versions-Enabled": "true"})
+	assert.NoError(t, err)
+
+	var vids []string
+	for _, color := range []string{"red", "blue"} {
+		headers, err := c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader(color), false, "", "text/plain", swift.Headers{
+			"X-Object-Meta-Color": color,
+			"Content-Disposition": "attachment; filename=" + color + ".txt",
+		})
+		assert.NoError(t, err)
+		vids = append(vids, headers["X-Object-Version-Id"])
+	}
+
+	// The previous version keeps its metadata.
+	res := request(http.MethodHead, "version-id="+vids[0])
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "red", res.Header.Get("X-Object-Meta-Color"))
+	assert.Equal(t, "attachment; filename=red.txt", res.Header.Get("Content-Disposition"))
+
+	res = request(http.MethodGet, "version-id="+vids[1])
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "blue", res.Header.Get("X-Object-Meta-Color"))
+
+	// The restored version gets its metadata back.
+	res = request(http.MethodDelete, "version-id="+vids[1])
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	_, headers, err := c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "red", headers["X-Object-Meta-Color"])
+	assert.Equal(t, "attachment; filename=red.txt", headers["Content-Disposition"])
+
+	// The deletion archives the current version along with its metadata.
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+
+	res = request(http.MethodHead, "version-id="+vids[0])
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "red", res.Header.Get("X-Object-Meta-Color"))
+}
+
+func TestObjectVersionsListing(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	listing := func(query string) []map[string]interface{} {
+		req, err := http.NewRequest(http.MethodGet, c.StorageUrl+"/Xcontainer?versions&format=json&"+query, nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		defer res.Body.Close()
+
+		var versions []map[string]interface{}
+		err = json.NewDecoder(res.Body).Decode(&versions)
+		assert.NoError(t, err)
+		return versions
+	}
+	names := func(versions []map[string]interface{}) []string {
+		var names []string
+		for _, version := range versions {
+			if subdir, ok := version["subdir"]; ok {
+				names = append(names, subdir.(string))
+				continue
+			}
+			names = append(names, version["name"].(string)+"@"+version["version_id"].(string))
+		}
+		return names
+	}
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Versions-Enabled": "true"})
+	assert.NoError(t, err)
+
+	vids := map[string][]string{}
+	for _, name := range []string{"a1/b2/c3.txt", "a1/b2/c3.txt", "a1/c4.txt", "d5.txt", "d5.txt"} {
+		headers, err := c.ObjectPut(ctx, "Xcontainer", name, strings.NewReader(name), false, "", "text/plain", nil)
+		assert.NoError(t, err)
+		vids[name] = append([]string{headers["X-Object-Version-Id"]}, vids[name]...)
+	}
+
+	assert.Equal(t, []string{
+		"a1/b2/c3.txt@" + vids["a1/b2/c3.txt"][0],
+		"a1/b2/c3.txt@" + vids["a1/b2/c3.txt"][1],
+		"a1/c4.txt@" + vids["a1/c4.txt"][0],
+		"d5.txt@" + vids["d5.txt"][0],
+		"d5.txt@" + vids["d5.txt"][1],
+	}, names(listing("")))
+
+	// The limit applies to the versions.
+	assert.Equal(t, []string{
+		"a1/b2/c3.txt@" + vids["a1/b2/c3.txt"][1],
+		"a1/c4.txt@" + vids["a1/c4.txt"][0],
+	}, names(listing("limit=2&marker=a1/b2/c3.txt&version_marker="+vids["a1/b2/c3.txt"][0])))
+
+	assert.Equal(t, []string{"a1/", "d5.txt@" + vids["d5.txt"][0], "d5.txt@" + vids["d5.txt"][1]}, names(listing("delimiter=/")))
+	assert.Equal(t, []string{"a1/b2/", "a1/c4.txt@" + vids["a1/c4.txt"][0]}, names(listing("delimiter=/&prefix=a1/")))
+
+	// The objects are listed in reverse order, their versions from the newest.
+	assert.Equal(t, []string{
+		"d5.txt@" + vids["d5.txt"][0],
+		"d5.txt@" + vids["d5.txt"][1],
+		"a1/",
+	}, names(listing("delimiter=/&reverse=true")))
+
+	if authVersion != 3 {
+		return // The pages do not depend on the auth version.
+	}
+
+	// The versions of an object span several pages.
+	for i := 0; i < 1000; i++ {
+		headers, err := c.ObjectPut(ctx, "Xcontainer", "a1/c4.txt", strings.NewReader("v"), false, "", "text/plain", nil)
+		assert.NoError(t, err)
+		vids["a1/c4.txt"] = append([]string{headers["X-Object-Version-Id"]}, vids["a1/c4.txt"]...)
+	}
+
+	versions := names(listing("marker=a1/b2/c3.txt"))
+	if assert.Len(t, versions, 1003) {
+		assert.Equal(t, "a1/c4.txt@"+vids["a1/c4.txt"][0], versions[0])
+		assert.Equal(t, "a1/c4.txt@"+vids["a1/c4.txt"][1000], versions[1000])
+		assert.Equal(t, "d5.txt@"+vids["d5.txt"][1], versions[1002])
+	}
+	assert.Equal(t, []string{"a1/", "d5.txt@" + vids["d5.txt"][0], "d5.txt@" + vids["d5.txt"][1]}, names(listing("delimiter=/")))
+}
+
+func TestObjectVersionsFailedUpload(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Versions-Enabled": "true"})
+	assert.NoError(t, err)
+
+	headers, err := c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader("v1"), false, "", "text/plain", nil)
+	assert.NoError(t, err)
+	vid := headers["X-Object-Version-Id"]
+
+	// The current object is not archived by an upload not matching its Etag.
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c3.txt", strings.NewReader("v2"), false, "d41d8cd98f00b204e9800998ecf8427e", "text/plain", nil)
+	assert.Equal(t, swift.ObjectCorrupted, err)
+
+	req, err := http.NewRequest(http.MethodGet, c.StorageUrl+"/Xcontainer?versions&format=json", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+
+	var versions []map[string]interface{}
+	err = json.NewDecoder(res.Body).Decode(&versions)
+	res.Body.Close()
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 1) {
+		assert.Equal(t, vid, versions[0]["version_id"])
+		assert.Equal(t, true, versions[0]["is_latest"])
+	}
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", payload)
+}
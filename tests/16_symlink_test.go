@@ -0,0 +1,272 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// symlinkRequest performs a raw request on the object of Xcontainer of the connection's account.
+func symlinkRequest(t *testing.T, c *swift.Connection, method, object, query string, headers map[string]string) *http.Response {
+	req, err := http.NewRequest(method, c.StorageUrl+"/Xcontainer/"+object+"?"+query, nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	return res
+}
+
+func TestSymlink(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "releases/v1.txt", "v1", "text/plain")
+	assert.NoError(t, err)
+
+	res := symlinkRequest(t, c, http.MethodPut, "latest", "", map[string]string{"X-Symlink-Target": "Xcontainer/releases/v1.txt"})
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	// The symlink is followed.
+	res = symlinkRequest(t, c, http.MethodGet, "latest", "", nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.True(t, strings.HasSuffix(c.StorageUrl, strings.TrimSuffix(res.Header.Get("Content-Location"), "/Xcontainer/releases/v1.txt")))
+	assert.Equal(t, "v1", readAll(t, res))
+
+	_, headers, err := c.Object(ctx, "Xcontainer", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", headers["Content-Type"])
+	assert.Equal(t, "2", headers["Content-Length"])
+
+	// The symlink itself.
+	res = symlinkRequest(t, c, http.MethodHead, "latest", "symlink=get", nil)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Xcontainer/releases/v1.txt", res.Header.Get("X-Symlink-Target"))
+	assert.Equal(t, "application/symlink", res.Header.Get("Content-Type"))
+	assert.Equal(t, "0", res.Header.Get("Content-Length"))
+
+	var objects []map[string]interface{}
+	req, err := http.NewRequest(http.MethodGet, c.StorageUrl+"/Xcontainer?format=json&prefix=latest", nil)
+	assert.NoError(t, err)
+	req.Header.Set("X-Auth-Token", c.AuthToken)
+	res, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	err = json.NewDecoder(res.Body).Decode(&objects)
+	res.Body.Close()
+	assert.NoError(t, err)
+	if assert.Len(t, objects, 1) {
+		assert.Contains(t, objects[0]["symlink_path"], "/Xcontainer/releases/v1.txt")
+	}
+
+	//
+
+	// Invalid symlinks
+	res = symlinkRequest(t, c, http.MethodPut, "invalid", "", map[string]string{"X-Symlink-Target": "releases"})
+	res.Body.Close()
+	assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	_, err = c.ObjectPut(ctx, "Xcontainer", "invalid", strings.NewReader("body"), false, "", "", swift.Headers{"X-Symlink-Target": "Xcontainer/releases/v1.txt"})
+	if assert.Error(t, err) {
+		assert.Equal(t, 400, err.(*swift.Error).StatusCode)
+	}
+
+	// Dangling and looping symlinks
+	res = symlinkRequest(t, c, http.MethodPut, "dangling", "", map[string]string{"X-Symlink-Target": "Xcontainer/unknown"})
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "dangling")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	for object, target := range map[string]string{"loop1": "Xcontainer/loop2", "loop2": "Xcontainer/loop1"} {
+		res = symlinkRequest(t, c, http.MethodPut, object, "", map[string]string{"X-Symlink-Target": target})
+		res.Body.Close()
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+	}
+
+	res = symlinkRequest(t, c, http.MethodGet, "loop1", "", nil)
+	res.Body.Close()
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	// A chain of symlinks is followed up to the maximum
+	res = symlinkRequest(t, c, http.MethodPut, "chained", "", map[string]string{"X-Symlink-Target": "Xcontainer/latest"})
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "chained")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", payload)
+}
+
+func TestStaticSymlink(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "releases/v1.txt", "v1", "text/plain")
+	assert.NoError(t, err)
+
+	for etag, status := range map[string]int{
+		"d41d8cd98f00b204e9800998ecf8427e": http.StatusConflict,
+		"6654c734ccab8f440ff0825eb443dc7f": http.StatusCreated,
+	} {
+		res := symlinkRequest(t, c, http.MethodPut, "latest", "", map[string]string{
+			"X-Symlink-Target":      "Xcontainer/releases/v1.txt",
+			"X-Symlink-Target-Etag": etag,
+		})
+		res.Body.Close()
+		assert.Equal(t, status, res.StatusCode)
+	}
+
+	res := symlinkRequest(t, c, http.MethodPut, "missing", "", map[string]string{
+		"X-Symlink-Target":      "Xcontainer/releases/v2.txt",
+		"X-Symlink-Target-Etag": "6654c734ccab8f440ff0825eb443dc7f",
+	})
+	res.Body.Close()
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", payload)
+
+	// The target has changed
+	err = c.ObjectPutString(ctx, "Xcontainer", "releases/v1.txt", "v1.1", "text/plain")
+	assert.NoError(t, err)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "latest")
+	if assert.Error(t, err) {
+		assert.Equal(t, 409, err.(*swift.Error).StatusCode)
+	}
+
+	// The target has been deleted
+	err = c.ObjectDelete(ctx, "Xcontainer", "releases/v1.txt")
+	assert.NoError(t, err)
+
+	_, _, err = c.Object(ctx, "Xcontainer", "latest")
+	if assert.Error(t, err) {
+		assert.Equal(t, 409, err.(*swift.Error).StatusCode)
+	}
+}
+
+func TestSymlinkAccount(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	other := as(c, 1)
+	err = other.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "releases/v1.txt", "v1", "text/plain")
+	assert.NoError(t, err)
+
+	account := c.StorageUrl[strings.LastIndex(c.StorageUrl, "/")+1:]
+
+	err = other.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	res := symlinkRequest(t, other, http.MethodPut, "latest", "", map[string]string{
+		"X-Symlink-Target":         "Xcontainer/releases/v1.txt",
+		"X-Symlink-Target-Account": account,
+	})
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	// The target is read with the permissions of the requester.
+	_, err = other.ObjectGetString(ctx, "Xcontainer", "latest")
+	if assert.Error(t, err) {
+		assert.Equal(t, 403, err.(*swift.Error).StatusCode)
+	}
+
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-Container-Read": "other:visitor"})
+	assert.NoError(t, err)
+
+	payload, err := other.ObjectGetString(ctx, "Xcontainer", "latest")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", payload)
+}
+
+func TestSymlinkCopy(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "releases/v1.txt", "v1", "text/plain")
+	assert.NoError(t, err)
+
+	res := symlinkRequest(t, c, http.MethodPut, "latest", "", map[string]string{"X-Symlink-Target": "Xcontainer/releases/v1.txt"})
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	// The target of the symlink is copied.
+	res = symlinkRequest(t, c, "COPY", "latest", "", map[string]string{"Destination": "Xcontainer/copy"})
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res = symlinkRequest(t, c, http.MethodHead, "copy", "symlink=get", nil)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, res.Header.Get("X-Symlink-Target"))
+	assert.Equal(t, "text/plain", res.Header.Get("Content-Type"))
+	assert.Equal(t, "2", res.Header.Get("Content-Length"))
+
+	// The symlink itself is copied.
+	res = symlinkRequest(t, c, "COPY", "latest", "symlink=get", map[string]string{"Destination": "Xcontainer/link"})
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	res = symlinkRequest(t, c, http.MethodHead, "link", "symlink=get", nil)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Xcontainer/releases/v1.txt", res.Header.Get("X-Symlink-Target"))
+	assert.Equal(t, "application/symlink", res.Header.Get("Content-Type"))
+
+	err = c.ObjectPutString(ctx, "Xcontainer", "releases/v1.txt", "v1.1", "text/plain")
+	assert.NoError(t, err)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "link")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.1", payload)
+
+	payload, err = c.ObjectGetString(ctx, "Xcontainer", "copy")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", payload)
+}
@@ -0,0 +1,249 @@
+package tests
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ncw/swift/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// tarball returns a tar archive of the given files, gzipped when compress is true.
+func tarball(t *testing.T, files map[string]string, compress bool) *bytes.Buffer {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+
+	gz := gzip.NewWriter(&buf)
+	if compress {
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		assert.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+
+	if compress {
+		assert.NoError(t, gz.Close())
+	}
+	return &buf
+}
+
+func TestBulkDelete(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	for _, name := range []string{"a1/b2/c3.txt", "a1/b2/c4 with space.txt", "a1/b2/c5.txt"} {
+		err = c.ObjectPutString(ctx, "Xcontainer", name, "content", "text/plain")
+		assert.NoError(t, err)
+	}
+
+	result, err := c.BulkDelete(ctx, "Xcontainer", []string{"a1/b2/c3.txt", "a1/b2/c4 with space.txt", "unknown"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, result.NumberDeleted)
+	assert.EqualValues(t, 1, result.NumberNotFound)
+	assert.Empty(t, result.Errors)
+
+	names, err := c.ObjectNames(ctx, "Xcontainer", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1/b2/c5.txt"}, names)
+
+	//
+
+	// A container must be empty to be deleted.
+	request := func(accept, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, c.StorageUrl+"?bulk-delete", strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+		req.Header.Set("Accept", accept)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	res := request("text/plain", "/Xcontainer\n")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "Number Deleted: 0\nNumber Not Found: 0\nResponse Body: \nResponse Status: 400 Bad Request\nErrors:\n/Xcontainer, 409 Conflict\n", readAll(t, res))
+
+	res = request("application/xml", "/Xcontainer/a1/b2/c5.txt\n/Xcontainer\n")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	payload := readAll(t, res)
+	assert.Contains(t, payload, "<delete>\n<number_deleted>2</number_deleted>\n<number_not_found>0</number_not_found>\n")
+	assert.Contains(t, payload, "<response_status>200 OK</response_status>")
+
+	_, _, err = c.Container(ctx, "Xcontainer")
+	assert.Equal(t, swift.ContainerNotFound, err)
+
+	// The account itself can not be deleted or created.
+	for _, method := range []string{http.MethodDelete, http.MethodPut} {
+		req, err := http.NewRequest(method, c.StorageUrl, strings.NewReader("/Ycontainer\n"))
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode, method)
+	}
+}
+
+func TestBulkDeleteVersioned(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "History", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Xcontainer", swift.Headers{"X-History-Location": "History"})
+	assert.NoError(t, err)
+	err = c.ContainerCreate(ctx, "Ycontainer", swift.Headers{})
+	assert.NoError(t, err)
+	err = c.ContainerUpdate(ctx, "Ycontainer", swift.Headers{"X-Versions-Enabled": "true"})
+	assert.NoError(t, err)
+
+	for _, container := range []string{"Xcontainer", "Ycontainer"} {
+		err = c.ObjectPutString(ctx, container, "a1/b2/c3.txt", "v1", "text/plain")
+		assert.NoError(t, err)
+
+		result, err := c.BulkDelete(ctx, container, []string{"a1/b2/c3.txt"})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, result.NumberDeleted)
+		assert.Empty(t, result.Errors)
+	}
+
+	// The deleted objects are archived like a single deletion does.
+	objects, err := c.Objects(ctx, "History", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, objects, 2) {
+		assert.Equal(t, "application/x-deleted;swift_versions_deleted=1", objects[1].ContentType)
+	}
+
+	request := func(method, query, body string) *http.Response {
+		req, err := http.NewRequest(method, c.StorageUrl+query, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("X-Auth-Token", c.AuthToken)
+		req.Header.Set("Accept", "text/plain")
+
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res
+	}
+
+	res := request(http.MethodGet, "/Ycontainer?versions&format=json", "")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 2, strings.Count(readAll(t, res), `"version_id"`))
+
+	// The containers with versions can not be deleted.
+	res = request(http.MethodPost, "?bulk-delete", "/Ycontainer\n")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, readAll(t, res), "/Ycontainer, 409 Conflict")
+}
+
+func TestBulkExtract(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	// The containers are created from the archive's directories in the account.
+	archive := tarball(t, map[string]string{
+		"Xcontainer/a1/b2/c3.txt": "c3",
+		"Ycontainer/c4.json":      "{}",
+	}, true)
+
+	result, err := c.BulkUpload(ctx, "", archive, swift.UploadTarGzip, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, result.NumberCreated)
+
+	payload, err := c.ObjectGetString(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "c3", payload)
+
+	_, headers, err := c.Object(ctx, "Ycontainer", "c4.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", headers["Content-Type"])
+
+	// The files are extracted under the path.
+	archive = tarball(t, map[string]string{
+		"b2/c5.txt":   "c5",
+		"./b2/c6.txt": "c6",
+	}, false)
+
+	result, err = c.BulkUpload(ctx, "Xcontainer/a1", archive, swift.UploadTar, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, result.NumberCreated)
+
+	names, err := c.ObjectNames(ctx, "Xcontainer", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1/b2/c3.txt", "a1/b2/c5.txt", "a1/b2/c6.txt"}, names)
+
+	// An overwrite replaces the metadata and the manifest of the extracted file.
+	err = c.ObjectUpdate(ctx, "Xcontainer", "a1/b2/c3.txt", swift.Headers{"X-Object-Meta-Color": "blue"})
+	assert.NoError(t, err)
+	_, err = c.ObjectPut(ctx, "Xcontainer", "a1/b2/c7.txt", strings.NewReader(""), false, "", "", swift.Headers{
+		"X-Object-Manifest": "Xcontainer/a1/b2/c",
+	})
+	assert.NoError(t, err)
+
+	archive = tarball(t, map[string]string{
+		"b2/c3.txt": "c3bis",
+		"b2/c7.txt": "c7",
+	}, false)
+
+	result, err = c.BulkUpload(ctx, "Xcontainer/a1", archive, swift.UploadTar, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, result.NumberCreated)
+
+	_, headers, err = c.Object(ctx, "Xcontainer", "a1/b2/c3.txt")
+	assert.NoError(t, err)
+	assert.Empty(t, headers.ObjectMetadata())
+
+	err = c.ObjectDelete(ctx, "Xcontainer", "a1/b2/c7.txt")
+	assert.NoError(t, err)
+	_, _, err = c.Object(ctx, "Xcontainer", "a1/b2/c7.txt")
+	assert.Equal(t, swift.ObjectNotFound, err)
+
+	//
+
+	// The containers are not created under a container.
+	archive = tarball(t, map[string]string{"c7.txt": "c7"}, false)
+
+	result, err = c.BulkUpload(ctx, "Zcontainer", archive, swift.UploadTar, nil)
+	assert.Error(t, err)
+	assert.EqualValues(t, 0, result.NumberCreated)
+	assert.Contains(t, result.Errors, "/Zcontainer/c7.txt")
+
+	result, err = c.BulkUpload(ctx, "Xcontainer", strings.NewReader("invalid"), swift.UploadTar, nil)
+	assert.Error(t, err)
+	assert.EqualValues(t, 0, result.NumberCreated)
+}
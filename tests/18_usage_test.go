@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/mdouchement/logger"
+	"github.com/mdouchement/openstackswift/internal/database"
+	"github.com/mdouchement/openstackswift/internal/model"
+	"github.com/mdouchement/openstackswift/internal/scheduler"
+	"github.com/mdouchement/openstackswift/internal/storage"
+	"github.com/ncw/swift/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerUsage(t *testing.T) {
+	c, cleanup := setup()
+	defer cleanup()
+
+	ctx := context.Background()
+	err := c.Authenticate(ctx)
+	assert.NoError(t, err)
+
+	//
+
+	err = c.ContainerCreate(ctx, "Xcontainer", swift.Headers{})
+	assert.NoError(t, err)
+
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1.txt", "0123456789", "text/plain")
+	assert.NoError(t, err)
+	err = c.ObjectPutString(ctx, "Xcontainer", "a2.txt", "01234", "text/plain")
+	assert.NoError(t, err)
+
+	info, _, err := c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), info.Count)
+	assert.Equal(t, int64(15), info.Bytes)
+
+	// The usage does not depend on the listing parameters.
+	_, headers, err := c.Call(ctx, c.StorageUrl, swift.RequestOpts{
+		Container:  "Xcontainer",
+		Operation:  "GET",
+		Parameters: url.Values{"limit": {"1"}, "prefix": {"a1"}},
+		NoResponse: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", headers["X-Container-Object-Count"])
+	assert.Equal(t, "15", headers["X-Container-Bytes-Used"])
+
+	//
+
+	err = c.ObjectPutString(ctx, "Xcontainer", "a1.txt", "012", "text/plain")
+	assert.NoError(t, err)
+	_, err = c.ObjectCopy(ctx, "Xcontainer", "a2.txt", "Xcontainer", "a3.txt", nil)
+	assert.NoError(t, err)
+
+	info, _, err = c.Container(ctx, "Xcontainer")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), info.Count)
+	assert.Equal(t, int64(13), info.Bytes)
+
+	//
+
+	err = c.ObjectDelete(ctx, "Xcontainer", "a2.txt")
+	assert.NoError(t, err)
+
+	containers, err := c.Containers(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []swift.Container{{Name: "Xcontainer", Count: 2, Bytes: 8}}, containers)
+
+	account, _, err := c.Account(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), account.Containers)
+	assert.Equal(t, int64(2), account.Objects)
+	assert.Equal(t, int64(8), account.BytesUsed)
+}
+
+func TestContainerUsageRecount(t *testing.T) {
+	dbname, err := os.CreateTemp(os.TempDir(), "swift.db.")
+	assert.NoError(t, err)
+	dbname.Close()
+	defer os.RemoveAll(dbname.Name())
+
+	// A database written before the usage was maintained.
+	assert.NoError(t, database.StormInit(dbname.Name()))
+	db, err := storm.Open(dbname.Name(), database.StormCodec)
+	assert.NoError(t, err)
+	container := &model.Container{ProjectID: "test", Name: "Xcontainer"}
+	container.ID = "c1"
+	assert.NoError(t, db.Save(container))
+	for id, size := range map[string]int64{"o1": 10, "o2": 5} {
+		object := &model.Object{ContainerID: container.ID, Key: id, Size: size}
+		object.ID = id
+		assert.NoError(t, db.Save(object))
+	}
+	assert.NoError(t, db.Close())
+
+	// The usage is computed once when the database is opened.
+	usage := func() (int, int64) {
+		client, err := database.StormOpen(dbname.Name())
+		assert.NoError(t, err)
+		defer client.Close()
+
+		container, err := client.FindContainer("c1")
+		assert.NoError(t, err)
+		return container.Count, container.Bytes
+	}
+
+	count, bytes := usage()
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int64(15), bytes)
+
+	client, err := database.StormOpen(dbname.Name())
+	assert.NoError(t, err)
+	container, err = client.FindContainer("c1")
+	assert.NoError(t, err)
+	container.Count, container.Bytes = 42, 42
+	assert.NoError(t, client.Save(container))
+	assert.NoError(t, client.Close())
+
+	count, bytes = usage()
+	assert.Equal(t, 42, count)
+	assert.Equal(t, int64(42), bytes)
+
+	// The reindexation computes the usage again.
+	assert.NoError(t, database.StormReIndex(dbname.Name()))
+
+	count, bytes = usage()
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int64(15), bytes)
+}
+
+func TestContainerUsageExpiration(t *testing.T) {
+	dbname, err := os.CreateTemp(os.TempDir(), "swift.db.")
+	assert.NoError(t, err)
+	dbname.Close()
+	defer os.RemoveAll(dbname.Name())
+
+	workspace, err := os.MkdirTemp(os.TempDir(), "swift.")
+	assert.NoError(t, err)
+	defer os.RemoveAll(workspace)
+
+	db, err := database.StormOpen(dbname.Name())
+	assert.NoError(t, err)
+	defer db.Close()
+	backend := storage.NewFileSystem(workspace)
+
+	//
+
+	container := &model.Container{ProjectID: "test", Name: "Xcontainer"}
+	assert.NoError(t, db.Save(container))
+
+	for key, ttl := range map[string]time.Time{
+		"expired":   time.Now().Add(-time.Minute),
+		"alive":     time.Now().Add(time.Hour),
+		"permanent": {},
+	} {
+		wc, err := backend.Writer(container.Path(), key)
+		assert.NoError(t, err)
+		_, err = wc.Write([]byte(strings.Repeat("x", len(key))))
+		assert.NoError(t, err)
+		assert.NoError(t, wc.Commit())
+
+		assert.NoError(t, db.Save(&model.Object{ContainerID: container.ID, Key: key, Size: int64(len(key)), TTL: ttl}))
+	}
+
+	scheduler.Run(scheduler.Controller{
+		Logger:   logger.WrapLogrus(logrus.New()),
+		Database: db,
+		Storage:  backend,
+	})
+
+	_, err = db.FindObjectByKey(container.ID, "expired")
+	assert.True(t, db.IsNotFound(err))
+
+	container, err = db.FindContainer(container.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, container.Count)
+	assert.Equal(t, int64(len("alive")+len("permanent")), container.Bytes)
+}
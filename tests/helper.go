@@ -3,6 +3,7 @@ package tests
 import (
 	"fmt"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"regexp"
 	"time"
@@ -15,6 +16,22 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// authVersion is the auth version used by the connections.
+var authVersion = 3
+
+// users are the registered accounts, the first one is used by the connection returned by setup.
+// The users of another domain than Default can only authenticate with Keystone v3.
+var users = []struct {
+	domain   string
+	project  string
+	username string
+	password string
+}{
+	{domain: "Default", project: "test", username: "tester", password: "testing"},
+	{domain: "Default", project: "other", username: "visitor", password: "visiting"},
+	{domain: "Elsewhere", project: "other", username: "intruder", password: "intruding"},
+}
+
 func setup() (*swift.Connection, func()) {
 	log := logrus.New()
 	log.SetFormatter(&logger.LogrusTextFormatter{
@@ -47,15 +64,19 @@ func setup() (*swift.Connection, func()) {
 
 	//
 
+	for _, u := range users {
+		_, err = database.RegisterUser(db, u.domain, u.project, u.username, u.password)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	//
+
 	ctrl := webserver.Controller{
 		Logger:   logger.WrapLogrus(log),
 		Database: db,
 		Storage:  storage.NewFileSystem(workspace),
-
-		Tenant:   "test",
-		Domain:   "Default",
-		Username: "tester",
-		Password: "testing",
 	}
 	engine := webserver.EchoEngine(ctrl)
 
@@ -66,15 +87,8 @@ func setup() (*swift.Connection, func()) {
 
 	//
 
-	fmt.Println("Listen:", server.URL+"/v3")
-	c := &swift.Connection{
-		AuthUrl:  server.URL + "/v3",
-		Tenant:   ctrl.Tenant,
-		Domain:   ctrl.Domain,
-		UserName: ctrl.Username,
-		ApiKey:   ctrl.Password,
-		Region:   "RegionOne",
-	}
+	c := connection(server.URL, 0)
+	fmt.Println("Listen:", c.AuthUrl)
 
 	return c, func() {
 		server.Close()
@@ -85,3 +99,36 @@ func setup() (*swift.Connection, func()) {
 		os.RemoveAll(workspace)
 	}
 }
+
+// as returns a new connection to the same server authenticated with the i-th user.
+func as(c *swift.Connection, i int) *swift.Connection {
+	u, err := url.Parse(c.AuthUrl)
+	if err != nil {
+		panic(err)
+	}
+	u.Path = ""
+	return connection(u.String(), i)
+}
+
+// connection returns a connection to the server authenticated with the i-th user using the current authVersion.
+func connection(server string, i int) *swift.Connection {
+	c := &swift.Connection{
+		AuthVersion: authVersion,
+		Tenant:      users[i].project,
+		UserName:    users[i].username,
+		ApiKey:      users[i].password,
+		Region:      "RegionOne",
+	}
+
+	switch authVersion {
+	case 1:
+		c.AuthUrl = server + "/auth/v1.0"
+		c.UserName = users[i].project + ":" + users[i].username
+	case 2:
+		c.AuthUrl = server + "/v2.0"
+	default:
+		c.AuthUrl = server + "/v3"
+		c.Domain = users[i].domain
+	}
+	return c
+}
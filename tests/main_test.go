@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// TestMain runs the suite against each auth version, or only the one defined by SWIFT_AUTH_VERSION.
+func TestMain(m *testing.M) {
+	versions := []int{3, 2, 1}
+	if v := os.Getenv("SWIFT_AUTH_VERSION"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			panic(err)
+		}
+		versions = []int{version}
+	}
+
+	for _, version := range versions {
+		authVersion = version
+		fmt.Println("Auth version:", version)
+
+		if code := m.Run(); code != 0 {
+			os.Exit(code)
+		}
+	}
+	os.Exit(0)
+}